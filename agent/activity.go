@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the activity classification of an agent.
+type State int
+
+const (
+	StateUnknown State = iota
+	StateIdle
+	StateBusy
+	StateWaiting
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateBusy:
+		return "busy"
+	case StateWaiting:
+		return "waiting"
+	default:
+		return "unknown"
+	}
+}
+
+// busyCPUPercent is the CPU usage above which an agent counts as busy.
+const busyCPUPercent = 3.0
+
+type cpuSample struct {
+	ticks   uint64
+	at      time.Time
+	wasBusy bool
+}
+
+// ActivityTracker classifies agents as busy, idle or waiting, and measures
+// their CPU usage, by comparing CPU time between successive calls to Classify.
+type ActivityTracker struct {
+	mu      sync.Mutex
+	samples map[int]cpuSample
+	now     func() time.Time
+}
+
+func NewActivityTracker() *ActivityTracker {
+	return &ActivityTracker{samples: make(map[int]cpuSample), now: time.Now}
+}
+
+// Classify sets State, IsActive and CPUPercent on each agent using the
+// process snapshot procs. An agent is busy when it and its descendants used
+// more than busyCPUPercent since the previous sample, or a descendant is
+// currently running. An idle agent that owns the foreground of its terminal after
+// having been busy is waiting for input.
+func (t *ActivityTracker) Classify(agents []Agent, procs []Process) []Agent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tree := NewProcessTree(procs)
+
+	now := t.now()
+	seen := make(map[int]bool)
+	for i := range agents {
+		a := &agents[i]
+		seen[a.PID] = true
+
+		proc, ok := tree.Get(a.PID)
+		if !ok {
+			a.State = StateUnknown
+			a.IsActive = false
+			continue
+		}
+
+		ticks, running := proc.CPUTicks, false
+		for _, cp := range tree.Descendants(a.PID) {
+			ticks += cp.CPUTicks
+			running = running || cp.State == 'R' || cp.State == 'D'
+		}
+
+		prev, hadPrev := t.samples[a.PID]
+		a.CPUPercent = 0
+		if hadPrev && ticks >= prev.ticks {
+			a.CPUPercent = cpuPercent(ticks-prev.ticks, now.Sub(prev.at))
+		}
+		busy := running || a.CPUPercent > busyCPUPercent
+
+		switch {
+		case busy:
+			a.State = StateBusy
+		case prev.wasBusy && proc.TPGID == proc.PGRP:
+			a.State = StateWaiting
+		default:
+			a.State = StateIdle
+		}
+		a.IsActive = a.State == StateBusy
+
+		t.samples[a.PID] = cpuSample{
+			ticks:   ticks,
+			at:      now,
+			wasBusy: busy || a.State == StateWaiting,
+		}
+	}
+
+	for pid := range t.samples {
+		if !seen[pid] {
+			delete(t.samples, pid)
+		}
+	}
+	return agents
+}
+
+func cpuPercent(ticks uint64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(ticks) / clockTicks / elapsed.Seconds() * 100
+}
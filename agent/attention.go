@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// promptScanLines is how many trailing non-blank pane lines are searched for
+// a prompt. Permission dialogs are drawn at the bottom of the pane.
+const promptScanLines = 15
+
+var promptPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)[(\[]y/n[)\]]`),
+	regexp.MustCompile(`(?i)\bdo you want to\b`),
+	regexp.MustCompile(`(?i)\b(allow|deny|approve|reject)\b.*\b(once|always|session|command|edit|tool)\b`),
+	regexp.MustCompile(`(?i)\bpermission (required|needed|requested)\b`),
+	regexp.MustCompile(`(?i)\bpress enter\b`),
+	regexp.MustCompile(`(?i)\bwaiting for (your )?(input|answer|response|confirmation)\b`),
+	regexp.MustCompile(`^\s*[❯>]\s*\d+\.\s`),
+}
+
+// LooksLikePrompt reports whether the tail of captured pane text shows the
+// agent asking the user something: a permission prompt, a y/n question or a
+// numbered choice.
+func LooksLikePrompt(text string) bool {
+	var lines []string
+	all := strings.Split(text, "\n")
+	for i := len(all) - 1; i >= 0 && len(lines) < promptScanLines; i-- {
+		line := strings.TrimSpace(strings.Trim(all[i], "│┃║|"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for _, line := range lines {
+		for _, pattern := range promptPatterns {
+			if pattern.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// promptState captures pane and returns StateWaiting when it shows a prompt
+// and StateIdle otherwise. ok is false when the pane could not be captured.
+func promptState(pane Pane, capture func(Pane) (string, error)) (state State, ok bool) {
+	text, err := capture(pane)
+	if err != nil {
+		return StateUnknown, false
+	}
+	if LooksLikePrompt(text) {
+		return StateWaiting, true
+	}
+	return StateIdle, true
+}
+
+// PromptTracker refines the state of idle agents running in tmux using the
+// text of their pane: an idle agent showing a prompt is waiting for input,
+// one that does not is plain idle. Busy agents are never captured, and panes
+// that cannot be captured keep the CPU based classification. The pane of an
+// agent is captured only once after it stops being busy and the outcome is
+// reused until the agent is busy again, so idle agents cost no tmux call per
+// scan.
+type PromptTracker struct {
+	mu      sync.Mutex
+	capture func(Pane) (string, error)
+	states  map[int]State
+}
+
+func NewPromptTracker(capture func(Pane) (string, error)) *PromptTracker {
+	return &PromptTracker{capture: capture, states: make(map[int]State)}
+}
+
+// Refine sets the state of each idle agent in a pane from its last capture,
+// capturing the pane of agents seen idle for the first time since they were
+// busy.
+func (t *PromptTracker) Refine(agents []Agent) []Agent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[int]bool)
+	for i := range agents {
+		a := &agents[i]
+		seen[a.PID] = true
+		if !a.InPane() || a.State == StateBusy || a.State == StateUnknown {
+			delete(t.states, a.PID)
+			continue
+		}
+		if state, ok := t.states[a.PID]; ok {
+			a.State = state
+			continue
+		}
+		if state, ok := promptState(a.Pane, t.capture); ok {
+			a.State = state
+			t.states[a.PID] = state
+		}
+	}
+
+	for pid := range t.states {
+		if !seen[pid] {
+			delete(t.states, pid)
+		}
+	}
+	return agents
+}
+
+// NeedsAttention reports whether the agent is blocked on the user.
+func (a Agent) NeedsAttention() bool {
+	return a.State == StateWaiting
+}
+
+// CountNeedingAttention returns how many agents are blocked on the user.
+func CountNeedingAttention(agents []Agent) int {
+	n := 0
+	for _, a := range agents {
+		if a.NeedsAttention() {
+			n++
+		}
+	}
+	return n
+}
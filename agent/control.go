@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// restartTimeout bounds how long Restart waits for the old process to exit.
+const restartTimeout = 5 * time.Second
+
+// ErrNotInPane is returned for actions that need the agent's tmux pane.
+var ErrNotInPane = errors.New("agent is not running in a tmux pane")
+
+// Interrupt stops the agent's current turn the way Ctrl-C would: in a tmux
+// pane the keystroke is sent to the pane, otherwise the process gets SIGINT.
+func Interrupt(a Agent) error {
+	if a.InPane() {
+		if err := exec.Command("tmux", "send-keys", "-t", a.Pane.Target(), "C-c").Run(); err != nil {
+			return fmt.Errorf("send Ctrl-C to %s: %w", a.Pane, err)
+		}
+		return nil
+	}
+	if err := syscall.Kill(a.PID, syscall.SIGINT); err != nil {
+		return fmt.Errorf("interrupt agent %d: %w", a.PID, err)
+	}
+	return nil
+}
+
+// Terminate sends SIGTERM to the agent process.
+func Terminate(a Agent) error {
+	if err := syscall.Kill(a.PID, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("terminate agent %d: %w", a.PID, err)
+	}
+	return nil
+}
+
+// Restart stops the agent and runs its original command line again in the
+// same tmux pane. When the agent is the pane's own process the pane is
+// respawned; otherwise the agent is terminated and the command is typed into
+// the shell it was started from. A non-empty statusPath wraps the command in
+// ExitStatusCommand, as when it was launched.
+func Restart(a Agent, statusPath string) error {
+	if !a.InPane() {
+		return ErrNotInPane
+	}
+	if len(a.Cmdline) == 0 {
+		return fmt.Errorf("restart agent %d: command line unknown", a.PID)
+	}
+	command := ShellJoin(a.Cmdline)
+	if statusPath != "" {
+		command = ExitStatusCommand(a.Cmdline, statusPath)
+	}
+
+	if a.Pane.PID == a.PID {
+		args := []string{"respawn-pane", "-k", "-t", a.Pane.Target()}
+		if a.WorkingDir != "" {
+			args = append(args, "-c", a.WorkingDir)
+		}
+		if err := exec.Command("tmux", append(args, command)...).Run(); err != nil {
+			return fmt.Errorf("respawn pane %s: %w", a.Pane, err)
+		}
+		return nil
+	}
+
+	if err := Terminate(a); err != nil {
+		return err
+	}
+	if err := waitForExit(a.PID, restartTimeout); err != nil {
+		return err
+	}
+	if err := exec.Command("tmux", "send-keys", "-t", a.Pane.Target(), command, "Enter").Run(); err != nil {
+		return fmt.Errorf("relaunch agent in %s: %w", a.Pane, err)
+	}
+	return nil
+}
+
+func waitForExit(pid int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("agent %d did not exit within %s", pid, timeout)
+}
+
+// ShellJoin quotes argv so a POSIX shell parses it back into the same words.
+func ShellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_./=:,@%+", c)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
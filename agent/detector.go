@@ -2,14 +2,9 @@ package agent
 
 import (
 	"errors"
-	"os/exec"
 	"strconv"
 	"strings"
-)
-
-const (
-	agentName    = "OpenCode"
-	agentPattern = "opencode"
+	"time"
 )
 
 var ErrNoAgentsFound = errors.New("no agents found")
@@ -19,26 +14,97 @@ type Agent struct {
 	WorkingDir string
 	PID        int
 	IsActive   bool
+	State      State
+	Cmdline    []string
+	Metadata   Metadata
+	// SubProcesses are the descendants of the agent process, e.g. LSP
+	// servers, tool invocations and helper workers.
+	SubProcesses []Process
+	// Pane is the tmux pane the agent runs in, if any.
+	Pane Pane
+	// Repo, Issue and Command link the agent to the GitHub issue it works
+	// on. They are zero when no link could be inferred.
+	Repo    string
+	Issue   int
+	Command string
+
+	// Resource usage of the agent process together with its sub-processes.
+	// CPUPercent is measured between refreshes and is zero on the first one.
+	StartTime  time.Time
+	CPUPercent float64
+	RSS        int64
+
+	// Git is the status of the checkout in WorkingDir, nil outside git.
+	Git *GitStatus
 }
 
-func DetectAgents() ([]Agent, error) {
-	pids, err := findAgentPIDs()
+// Detector finds the agents currently running.
+type Detector interface {
+	Detect() ([]Agent, error)
+}
+
+// ProcDetector detects agents from a procfs snapshot. Each optional stage can
+// be replaced or disabled by setting its field; a nil stage is skipped.
+type ProcDetector struct {
+	Scanner *ProcScanner
+	Tracker *ActivityTracker
+	// ListPanes lists tmux panes for mapping agents to panes.
+	ListPanes func() ([]Pane, error)
+	// Prompts reads pane text for detecting prompts.
+	Prompts *PromptTracker
+	// GitStatus reads the status of a working directory.
+	GitStatus func(dir string) (*GitStatus, error)
+}
+
+// NewProcDetector returns a detector reading root (DefaultProcRoot when
+// empty) with every stage enabled.
+func NewProcDetector(root string) *ProcDetector {
+	return &ProcDetector{
+		Scanner:   NewProcScanner(root),
+		Tracker:   NewActivityTracker(),
+		ListPanes: ListPanes,
+		Prompts:   NewPromptTracker(capturePromptLines),
+		GitStatus: ReadGitStatus,
+	}
+}
+
+func (d *ProcDetector) Detect() ([]Agent, error) {
+	procs, err := d.Scanner.Scan()
 	if err != nil {
 		return nil, err
 	}
-	if len(pids) == 0 {
+	agents := FindAgents(procs)
+	if len(agents) == 0 {
 		return nil, ErrNoAgentsFound
 	}
-	return buildAgentList(pids)
+	// Agents outside tmux are still reported when no server is running.
+	if d.ListPanes != nil {
+		if panes, err := d.ListPanes(); err == nil {
+			agents = AttachPanes(agents, panes, NewProcessTree(procs))
+		}
+	}
+	agents = LinkIssues(agents)
+	if d.GitStatus != nil {
+		agents = AttachGitStatus(agents, d.GitStatus)
+	}
+	if d.Tracker != nil {
+		agents = d.Tracker.Classify(agents, procs)
+	}
+	if d.Prompts != nil {
+		agents = d.Prompts.Refine(agents)
+	}
+	return agents, nil
 }
 
-func findAgentPIDs() ([]int, error) {
-	cmd := exec.Command("pgrep", "-f", agentPattern)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-	return ParsePIDs(string(output))
+func capturePromptLines(p Pane) (string, error) {
+	return CapturePane(p, promptScanLines*2)
+}
+
+var defaultDetector = NewProcDetector(DefaultProcRoot)
+
+// DetectAgents runs the default detector against the live system.
+func DetectAgents() ([]Agent, error) {
+	return defaultDetector.Detect()
 }
 
 func ParsePIDs(output string) ([]int, error) {
@@ -57,45 +123,42 @@ func ParsePIDs(output string) ([]int, error) {
 	return pids, nil
 }
 
-func buildAgentList(pids []int) ([]Agent, error) {
-	seen := make(map[string]bool)
-	var agents []Agent
+// FindAgents matches a process snapshot against the registered kinds. Only
+// root agent processes are reported: an agent process below another agent
+// process, such as a worker forked by a wrapper script, is attached to its
+// root as a sub-process instead.
+func FindAgents(procs []Process) []Agent {
+	tree := NewProcessTree(procs)
+	isAgent := func(p Process) bool {
+		_, ok := MatchKind(p.Cmdline)
+		return ok
+	}
 
-	for _, pid := range pids {
-		workingDir, err := getWorkingDir(pid)
-		if err != nil {
+	var agents []Agent
+	for _, p := range procs {
+		kind, ok := MatchKind(p.Cmdline)
+		if !ok || tree.HasAncestor(p.PID, isAgent) {
 			continue
 		}
 
-		key := workingDir
-		if key == "" {
-			key = strconv.Itoa(pid)
+		a := Agent{
+			Name:         kind.Name,
+			WorkingDir:   p.Cwd,
+			PID:          p.PID,
+			Cmdline:      p.Cmdline,
+			SubProcesses: tree.Descendants(p.PID),
+			StartTime:    p.StartTime,
+			RSS:          p.RSS,
 		}
-		if seen[key] {
-			continue
+		for _, sub := range a.SubProcesses {
+			a.RSS += sub.RSS
 		}
-		seen[key] = true
-
-		agents = append(agents, Agent{
-			Name:       agentName,
-			WorkingDir: workingDir,
-			PID:        pid,
-		})
-	}
-	return agents, nil
-}
-
-func getWorkingDir(pid int) (string, error) {
-	cmd := exec.Command("pwdx", strconv.Itoa(pid))
-	out, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	parts := strings.Fields(string(out))
-	if len(parts) < 2 {
-		return "", nil
+		if kind.Extract != nil {
+			a.Metadata = kind.Extract(p.Cmdline)
+		}
+		agents = append(agents, a)
 	}
-	return strings.TrimSpace(parts[1]), nil
+	return agents
 }
 
 func FilterActive(agents []Agent, activeOnly bool) []Agent {
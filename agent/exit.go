@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ExitStatusCommand returns a shell line that runs argv and then writes its
+// exit status to path. The line goes through sh so it works whatever the
+// interactive shell of the tmux pane is.
+func ExitStatusCommand(argv []string, path string) string {
+	script := ShellJoin(argv) + "; echo $? > " + ShellJoin([]string{path})
+	return ShellJoin([]string{"sh", "-c", script})
+}
+
+// Stopped reports whether an exit status written by ExitStatusCommand is that
+// of an agent killed by SIGINT or SIGTERM, i.e. stopped rather than failed.
+func Stopped(status int) bool {
+	return status == 128+int(syscall.SIGINT) || status == 128+int(syscall.SIGTERM)
+}
+
+// ExitStatusPath returns where the exit status of command run for issue in
+// repo is written inside dir.
+func ExitStatusPath(dir, repo string, issue int, command string) string {
+	name := fmt.Sprintf("%s-%d-%s", repo, issue, strings.TrimPrefix(command, "/"))
+	return filepath.Join(dir, strings.ReplaceAll(name, "/", "-")+".status")
+}
+
+// ReadExitStatus returns the status written by ExitStatusCommand and removes
+// the file, so a later run of the same command does not see it.
+func ReadExitStatus(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	os.Remove(path)
+	status, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse exit status in %s: %w", path, err)
+	}
+	return status, nil
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitStatus summarises the git checkout an agent works in.
+type GitStatus struct {
+	Branch      string
+	HasUpstream bool
+	Ahead       int
+	Behind      int
+	Files       []ChangedFile
+	LastCommit  string
+}
+
+// ChangedFile is one entry of git status: XY is the two-letter status code,
+// e.g. " M", "A ", "??".
+type ChangedFile struct {
+	XY   string
+	Path string
+}
+
+// Dirty returns the number of changed and untracked files.
+func (g GitStatus) Dirty() int {
+	return len(g.Files)
+}
+
+// ReadGitStatus runs git in dir and returns its status. It does not refresh
+// the index, so it never holds index.lock while the agent commits.
+func ReadGitStatus(dir string) (*GitStatus, error) {
+	out, err := exec.Command("git", "--no-optional-locks", "-C", dir, "status", "--porcelain=v2", "--branch", "--untracked-files=normal").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status in %s: %w", dir, err)
+	}
+	status := ParseGitStatus(string(out))
+
+	// A fresh repository has no commits, which is not an error worth reporting.
+	if subject, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%s").Output(); err == nil {
+		status.LastCommit = strings.TrimSpace(string(subject))
+	}
+	return status, nil
+}
+
+// ParseGitStatus parses git status --porcelain=v2 --branch output.
+func ParseGitStatus(output string) *GitStatus {
+	status := &GitStatus{}
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.HasUpstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "1 "):
+			if fields := strings.SplitN(line, " ", 9); len(fields) == 9 {
+				status.Files = append(status.Files, ChangedFile{XY: strings.ReplaceAll(fields[1], ".", " "), Path: fields[8]})
+			}
+		case strings.HasPrefix(line, "2 "):
+			if fields := strings.SplitN(line, " ", 10); len(fields) == 10 {
+				path, _, _ := strings.Cut(fields[9], "\t")
+				status.Files = append(status.Files, ChangedFile{XY: strings.ReplaceAll(fields[1], ".", " "), Path: path})
+			}
+		case strings.HasPrefix(line, "u "):
+			if fields := strings.SplitN(line, " ", 11); len(fields) == 11 {
+				status.Files = append(status.Files, ChangedFile{XY: fields[1], Path: fields[10]})
+			}
+		case strings.HasPrefix(line, "? "):
+			status.Files = append(status.Files, ChangedFile{XY: "??", Path: strings.TrimPrefix(line, "? ")})
+		}
+	}
+	return status
+}
+
+// AttachGitStatus sets Git on every agent with a working directory, reading
+// each directory once. Directories that are not git checkouts are skipped.
+func AttachGitStatus(agents []Agent, read func(dir string) (*GitStatus, error)) []Agent {
+	cache := make(map[string]*GitStatus)
+	for i := range agents {
+		dir := agents[i].WorkingDir
+		if dir == "" {
+			continue
+		}
+		status, ok := cache[dir]
+		if !ok {
+			status, _ = read(dir)
+			cache[dir] = status
+		}
+		agents[i].Git = status
+	}
+	return agents
+}
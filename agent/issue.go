@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// windowNamePattern matches windows created by the command dialog, e.g.
+	// "opencode-/tdd-42".
+	windowNamePattern = regexp.MustCompile(`^[^-/]+-(/[^\s]+)-(\d+)$`)
+	// promptPattern matches prompts such as "/tdd 42" or "/implement #42".
+	promptPattern = regexp.MustCompile(`^\s*(/[^\s]+)\s+#?(\d+)\b`)
+	// remoteURLPattern extracts owner/name from GitHub remote URLs in both
+	// https and scp-like ssh form.
+	remoteURLPattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+)
+
+// ParseWindowName extracts the command and issue number from a tmux window
+// name of the form "<agent>-<command>-<issue>".
+func ParseWindowName(name string) (command string, issue int, ok bool) {
+	return parseCommandAndIssue(windowNamePattern, name)
+}
+
+// ParsePrompt extracts the command and issue number from an agent prompt
+// such as "/tdd 42".
+func ParsePrompt(prompt string) (command string, issue int, ok bool) {
+	return parseCommandAndIssue(promptPattern, prompt)
+}
+
+func parseCommandAndIssue(pattern *regexp.Regexp, s string) (string, int, bool) {
+	match := pattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", 0, false
+	}
+	issue, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], issue, true
+}
+
+// LinkIssues infers Repo, Issue and Command for each agent. The repo comes
+// from the origin remote of the working directory; the command and issue come
+// from the agent's prompt, falling back to its tmux window name.
+func LinkIssues(agents []Agent) []Agent {
+	repos := make(map[string]string)
+	for i := range agents {
+		a := &agents[i]
+
+		if a.WorkingDir != "" {
+			repo, ok := repos[a.WorkingDir]
+			if !ok {
+				repo = RepoFromDir(a.WorkingDir)
+				repos[a.WorkingDir] = repo
+			}
+			a.Repo = repo
+		}
+
+		if command, issue, ok := ParsePrompt(a.Metadata["prompt"]); ok {
+			a.Command, a.Issue = command, issue
+		} else if command, issue, ok := ParseWindowName(a.Pane.Window); ok {
+			a.Command, a.Issue = command, issue
+		}
+	}
+	return agents
+}
+
+// RepoFromDir returns the GitHub "owner/name" of the origin remote of the
+// git checkout containing dir, or "" when it cannot be determined.
+func RepoFromDir(dir string) string {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return ""
+	}
+	url := originURL(filepath.Join(gitDir, "config"))
+	match := remoteURLPattern.FindStringSubmatch(url)
+	if match == nil {
+		return ""
+	}
+	return match[1] + "/" + match[2]
+}
+
+// findGitDir walks up from dir to the repository's git directory. Linked
+// worktrees have a .git file pointing at a per-worktree directory whose
+// commondir holds the shared config.
+func findGitDir(dir string) string {
+	for {
+		candidate := filepath.Join(dir, ".git")
+		info, err := os.Stat(candidate)
+		if err == nil && info.IsDir() {
+			return candidate
+		}
+		if err == nil {
+			return resolveGitFile(candidate)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func resolveGitFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		return filepath.Clean(commonDir)
+	}
+	return gitDir
+}
+
+func originURL(configPath string) string {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Metadata holds kind-specific details extracted from an agent's command line,
+// e.g. the model or the prompt it was started with.
+type Metadata map[string]string
+
+// Kind describes one family of agent processes.
+type Kind struct {
+	// Name is shown in the UI and stored in Agent.Name.
+	Name string
+	// Match reports whether the argv belongs to this kind.
+	Match func(argv []string) bool
+	// Extract is optional and pulls metadata out of the argv.
+	Extract func(argv []string) Metadata
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Kind
+)
+
+func init() {
+	Register(Kind{Name: "OpenCode", Match: MatchCommand("opencode"), Extract: ExtractFlags("model", "prompt")})
+	Register(Kind{Name: "Claude Code", Match: MatchCommand("claude"), Extract: ExtractFlags("model", "p", "print")})
+	Register(Kind{Name: "aider", Match: MatchCommand("aider"), Extract: ExtractFlags("model", "message")})
+	Register(Kind{Name: "Codex CLI", Match: MatchCommand("codex"), Extract: ExtractFlags("model")})
+	Register(Kind{Name: "Gemini CLI", Match: MatchCommand("gemini"), Extract: ExtractFlags("model", "prompt")})
+	Register(Kind{Name: "goose", Match: MatchCommand("goose")})
+}
+
+// Register adds a kind to the registry. Kinds are matched in registration
+// order, so the first kind whose matcher fires wins. Registering a kind with
+// an existing name replaces it in place.
+func Register(k Kind) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name == k.Name {
+			registry[i] = k
+			return
+		}
+	}
+	registry = append(registry, k)
+}
+
+// Unregister removes the kind named name from the registry, if present.
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name == name {
+			registry = append(registry[:i], registry[i+1:]...)
+			return
+		}
+	}
+}
+
+// Kinds returns a copy of the registered kinds in match order.
+func Kinds() []Kind {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Kind(nil), registry...)
+}
+
+// MatchKind returns the first registered kind that matches argv.
+func MatchKind(argv []string) (Kind, bool) {
+	for _, k := range Kinds() {
+		if k.Match != nil && k.Match(argv) {
+			return k, true
+		}
+	}
+	return Kind{}, false
+}
+
+var interpreters = map[string]bool{
+	"node":    true,
+	"bun":     true,
+	"deno":    true,
+	"python":  true,
+	"python3": true,
+	"uv":      true,
+	"uvx":     true,
+	"npx":     true,
+	"sh":      true,
+	"bash":    true,
+	"zsh":     true,
+}
+
+// MatchCommand returns a matcher that fires when the executable, or the
+// script run by a known interpreter such as node, python or bash, is named after
+// one of names. A name also matches wrappers like "opencode-secure".
+func MatchCommand(names ...string) func(argv []string) bool {
+	return func(argv []string) bool {
+		cmd := commandName(argv)
+		if cmd == "" {
+			return false
+		}
+		for _, name := range names {
+			if cmd == name || strings.HasPrefix(cmd, name+"-") || strings.HasPrefix(cmd, name+".") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func commandName(argv []string) string {
+	if len(argv) == 0 {
+		return ""
+	}
+	cmd := filepath.Base(argv[0])
+	if !interpreters[cmd] {
+		return cmd
+	}
+	for i := 1; i < len(argv); i++ {
+		arg := argv[i]
+		if arg == "-m" && i+1 < len(argv) {
+			return filepath.Base(argv[i+1])
+		}
+		if strings.HasPrefix(arg, "-") || arg == "run" || arg == "x" {
+			continue
+		}
+		return filepath.Base(arg)
+	}
+	return cmd
+}
+
+// ExtractFlags returns an extractor that collects the values of the given
+// long or short flags, accepting both "--flag value" and "--flag=value".
+func ExtractFlags(flags ...string) func(argv []string) Metadata {
+	return func(argv []string) Metadata {
+		md := Metadata{}
+		for i := 1; i < len(argv); i++ {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(argv[i], "-"), "=")
+			if !strings.HasPrefix(argv[i], "-") {
+				continue
+			}
+			for _, f := range flags {
+				if name != f {
+					continue
+				}
+				if !hasValue && i+1 < len(argv) {
+					value = argv[i+1]
+					i++
+				}
+				md[f] = value
+				break
+			}
+		}
+		if len(md) == 0 {
+			return nil
+		}
+		return md
+	}
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultProcRoot is where the kernel mounts procfs.
+const DefaultProcRoot = "/proc"
+
+// clockTicks is USER_HZ, the unit of the time fields in /proc/<pid>/stat.
+const clockTicks = 100
+
+// Process is a snapshot of one entry under /proc.
+type Process struct {
+	PID       int
+	PPID      int
+	PGRP      int
+	TPGID     int
+	State     byte
+	Cmdline   []string
+	Cwd       string
+	CPUTicks  uint64
+	StartTime time.Time
+	RSS       int64
+}
+
+// ProcScanner reads processes from a procfs tree. Root is injectable so
+// tests can point the scanner at a fake tree on disk.
+type ProcScanner struct {
+	Root     string
+	PageSize int64
+}
+
+func NewProcScanner(root string) *ProcScanner {
+	if root == "" {
+		root = DefaultProcRoot
+	}
+	return &ProcScanner{Root: root, PageSize: int64(os.Getpagesize())}
+}
+
+// Scan reads every numeric entry under Root in a single pass. Processes that
+// exit while being read are skipped.
+func (s *ProcScanner) Scan() ([]Process, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.Root, err)
+	}
+	bootTime := s.bootTime()
+
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		p, err := s.read(pid, bootTime)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// Process reads a single process.
+func (s *ProcScanner) Process(pid int) (Process, error) {
+	return s.read(pid, s.bootTime())
+}
+
+func (s *ProcScanner) read(pid int, bootTime time.Time) (Process, error) {
+	dir := filepath.Join(s.Root, strconv.Itoa(pid))
+
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+	stat, err := parseStat(string(data))
+	if err != nil {
+		return Process{}, err
+	}
+
+	p := Process{
+		PID:      pid,
+		PPID:     stat.PPID,
+		PGRP:     stat.PGRP,
+		TPGID:    stat.TPGID,
+		State:    stat.State,
+		CPUTicks: stat.CPUTicks,
+		RSS:      stat.RSSPages * s.PageSize,
+	}
+	if !bootTime.IsZero() {
+		p.StartTime = bootTime.Add(time.Duration(stat.StartTicks) * time.Second / clockTicks)
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		p.Cmdline = ParseCmdline(cmdline)
+	}
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+		p.Cwd = cwd
+	}
+	return p, nil
+}
+
+// bootTime reads the btime line of <root>/stat, returning the zero time when
+// it is unavailable.
+func (s *ProcScanner) bootTime() time.Time {
+	f, err := os.Open(filepath.Join(s.Root, "stat"))
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}
+			}
+			return time.Unix(secs, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// ParseCmdline splits the NUL-separated contents of /proc/<pid>/cmdline.
+func ParseCmdline(data []byte) []string {
+	trimmed := strings.TrimRight(string(data), "\x00")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "\x00")
+}
+
+// procStat holds the fields of /proc/<pid>/stat that the scanner uses.
+type procStat struct {
+	State      byte
+	PPID       int
+	PGRP       int
+	TPGID      int
+	CPUTicks   uint64
+	StartTicks uint64
+	RSSPages   int64
+}
+
+// parseStat parses the contents of /proc/<pid>/stat. The comm field may
+// contain spaces and parentheses, so fields are counted from the last ')'.
+func parseStat(data string) (procStat, error) {
+	end := strings.LastIndexByte(data, ')')
+	if end < 0 || end+2 >= len(data) {
+		return procStat{}, fmt.Errorf("malformed stat: %q", data)
+	}
+	fields := strings.Fields(data[end+2:])
+	// fields[0] is field 3 (state) in proc(5) numbering.
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat: %d fields", len(fields))
+	}
+	atoi := func(i int) int {
+		n, _ := strconv.Atoi(fields[i])
+		return n
+	}
+	atou := func(i int) uint64 {
+		n, _ := strconv.ParseUint(fields[i], 10, 64)
+		return n
+	}
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	return procStat{
+		State:      fields[0][0],
+		PPID:       atoi(1),
+		PGRP:       atoi(2),
+		TPGID:      atoi(5),
+		CPUTicks:   atou(11) + atou(12) + atou(13) + atou(14),
+		StartTicks: atou(19),
+		RSSPages:   rss,
+	}, nil
+}
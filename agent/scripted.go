@@ -0,0 +1,56 @@
+package agent
+
+import "sync"
+
+// ScriptStep is one result returned by a ScriptedDetector.
+type ScriptStep struct {
+	Agents []Agent
+	Err    error
+}
+
+// ScriptedDetector is a fake Detector for tests. Each call to Detect returns
+// the next step of the script; once the script is exhausted the last step is
+// repeated, so the agent set can change over a sequence of refreshes.
+type ScriptedDetector struct {
+	mu    sync.Mutex
+	steps []ScriptStep
+	calls int
+}
+
+func NewScriptedDetector(steps ...ScriptStep) *ScriptedDetector {
+	return &ScriptedDetector{steps: steps}
+}
+
+// Then appends a step to the script.
+func (d *ScriptedDetector) Then(agents []Agent, err error) *ScriptedDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.steps = append(d.steps, ScriptStep{Agents: agents, Err: err})
+	return d
+}
+
+func (d *ScriptedDetector) Detect() ([]Agent, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.steps) == 0 {
+		d.calls++
+		return nil, ErrNoAgentsFound
+	}
+	idx := d.calls
+	if idx >= len(d.steps) {
+		idx = len(d.steps) - 1
+	}
+	d.calls++
+	step := d.steps[idx]
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	return append([]Agent(nil), step.Agents...), nil
+}
+
+// Calls returns how many times Detect has been called.
+func (d *ScriptedDetector) Calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.calls
+}
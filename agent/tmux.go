@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tmuxPaneFormat is the -F format passed to tmux list-panes. Fields are tab
+// separated because session and window names may contain spaces.
+const tmuxPaneFormat = "#{pane_pid}\t#{session_name}\t#{window_index}\t#{window_name}\t#{pane_index}\t#{pane_id}"
+
+// Pane identifies the tmux pane an agent runs in.
+type Pane struct {
+	Session     string
+	WindowIndex int
+	Window      string
+	Index       int
+	ID          string
+	PID         int
+}
+
+// Target returns a tmux target for the pane, preferring the unique pane id.
+func (p Pane) Target() string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return fmt.Sprintf("%s:%d.%d", p.Session, p.WindowIndex, p.Index)
+}
+
+// String returns the pane in session:window.pane form.
+func (p Pane) String() string {
+	return fmt.Sprintf("%s:%s.%d", p.Session, p.Window, p.Index)
+}
+
+// ListPanes returns every pane of the running tmux server.
+func ListPanes() ([]Pane, error) {
+	out, err := exec.Command("tmux", "list-panes", "-a", "-F", tmuxPaneFormat).Output()
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-panes: %w", err)
+	}
+	return ParsePanes(string(out)), nil
+}
+
+// ParsePanes parses tmux list-panes output produced with tmuxPaneFormat.
+// Malformed lines are skipped.
+func ParsePanes(output string) []Pane {
+	var panes []Pane
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		windowIndex, _ := strconv.Atoi(fields[2])
+		paneIndex, _ := strconv.Atoi(fields[4])
+		panes = append(panes, Pane{
+			PID:         pid,
+			Session:     fields[1],
+			WindowIndex: windowIndex,
+			Window:      fields[3],
+			Index:       paneIndex,
+			ID:          fields[5],
+		})
+	}
+	return panes
+}
+
+// AttachPanes sets Pane on every agent whose process is a pane's process or
+// one of its descendants.
+func AttachPanes(agents []Agent, panes []Pane, tree *ProcessTree) []Agent {
+	byPID := make(map[int]Pane, len(panes))
+	for _, p := range panes {
+		byPID[p.PID] = p
+	}
+	for i := range agents {
+		if pane, ok := findPane(agents[i].PID, byPID, tree); ok {
+			agents[i].Pane = pane
+		}
+	}
+	return agents
+}
+
+func findPane(pid int, byPID map[int]Pane, tree *ProcessTree) (Pane, bool) {
+	visited := make(map[int]bool)
+	for !visited[pid] {
+		visited[pid] = true
+		if pane, ok := byPID[pid]; ok {
+			return pane, true
+		}
+		p, ok := tree.Get(pid)
+		if !ok {
+			break
+		}
+		pid = p.PPID
+	}
+	return Pane{}, false
+}
+
+// InPane reports whether the agent was mapped to a tmux pane.
+func (a Agent) InPane() bool {
+	return a.Pane.PID != 0
+}
+
+// CapturePane returns the last lines of the pane's visible history.
+func CapturePane(p Pane, lines int) (string, error) {
+	args := []string{"capture-pane", "-p", "-J", "-t", p.Target()}
+	if lines > 0 {
+		args = append(args, "-S", fmt.Sprintf("-%d", lines))
+	}
+	out, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux capture-pane %s: %w", p, err)
+	}
+	return LastLines(string(out), lines), nil
+}
+
+// LastLines returns at most n trailing lines of s, ignoring trailing blank
+// lines left by an unfilled pane.
+func LastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n "), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
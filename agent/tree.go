@@ -0,0 +1,68 @@
+package agent
+
+import "sort"
+
+// ProcessTree indexes a process snapshot by PID and parent.
+type ProcessTree struct {
+	byPID    map[int]Process
+	children map[int][]int
+}
+
+func NewProcessTree(procs []Process) *ProcessTree {
+	t := &ProcessTree{
+		byPID:    make(map[int]Process, len(procs)),
+		children: make(map[int][]int),
+	}
+	for _, p := range procs {
+		t.byPID[p.PID] = p
+		if p.PPID != p.PID {
+			t.children[p.PPID] = append(t.children[p.PPID], p.PID)
+		}
+	}
+	for ppid := range t.children {
+		sort.Ints(t.children[ppid])
+	}
+	return t
+}
+
+// Get returns the process with the given PID.
+func (t *ProcessTree) Get(pid int) (Process, bool) {
+	p, ok := t.byPID[pid]
+	return p, ok
+}
+
+// Children returns the direct children of pid in PID order.
+func (t *ProcessTree) Children(pid int) []Process {
+	var result []Process
+	for _, child := range t.children[pid] {
+		result = append(result, t.byPID[child])
+	}
+	return result
+}
+
+// Descendants returns every process below pid, breadth first.
+func (t *ProcessTree) Descendants(pid int) []Process {
+	var result []Process
+	queue := append([]int(nil), t.children[pid]...)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		result = append(result, t.byPID[child])
+		queue = append(queue, t.children[child]...)
+	}
+	return result
+}
+
+// HasAncestor reports whether any ancestor of pid satisfies match.
+func (t *ProcessTree) HasAncestor(pid int, match func(Process) bool) bool {
+	visited := map[int]bool{pid: true}
+	p, ok := t.byPID[pid]
+	for ok && !visited[p.PPID] {
+		visited[p.PPID] = true
+		p, ok = t.byPID[p.PPID]
+		if ok && match(p) {
+			return true
+		}
+	}
+	return false
+}
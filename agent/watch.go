@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is how often a Watcher re-scans when no interval is set.
+const DefaultWatchInterval = 2 * time.Second
+
+// Snapshot is the result of one scan by a Watcher, with the agents that
+// appeared and disappeared since the previous successful scan.
+type Snapshot struct {
+	Agents  []Agent
+	Started []Agent
+	Exited  []Agent
+	// Err is set when detection failed; Agents, Started and Exited are then
+	// empty and the previous snapshot stays the baseline for the next diff.
+	Err error
+}
+
+// Watcher re-runs a Detector on an interval and diffs each result against
+// the previous one.
+type Watcher struct {
+	Detector Detector
+	Interval time.Duration
+
+	mu   sync.Mutex
+	prev []Agent
+}
+
+func NewWatcher(d Detector, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{Detector: d, Interval: interval}
+}
+
+// Next waits for the interval and then scans. It is meant to be run as a
+// bubbletea command, one scan at a time.
+func (w *Watcher) Next() Snapshot {
+	time.Sleep(w.Interval)
+	return w.Scan()
+}
+
+// Scan detects agents immediately. Finding no agents is not an error for the
+// watcher: it is an empty snapshot in which every previous agent has exited.
+func (w *Watcher) Scan() Snapshot {
+	agents, err := w.Detector.Detect()
+	if err != nil && !errors.Is(err, ErrNoAgentsFound) {
+		return Snapshot{Err: fmt.Errorf("agent detection failed: %w", err)}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	started, exited := DiffAgents(w.prev, agents)
+	w.prev = agents
+	return Snapshot{Agents: agents, Started: started, Exited: exited}
+}
+
+// DiffAgents returns the agents in next but not in prev, and those in prev
+// but not in next. Agents are matched on PID and start time so a reused PID
+// counts as a new agent.
+func DiffAgents(prev, next []Agent) (started, exited []Agent) {
+	type key struct {
+		pid   int
+		start time.Time
+	}
+	keyOf := func(a Agent) key { return key{a.PID, a.StartTime.Truncate(time.Second)} }
+
+	before := make(map[key]bool, len(prev))
+	for _, a := range prev {
+		before[keyOf(a)] = true
+	}
+	after := make(map[key]bool, len(next))
+	for _, a := range next {
+		after[keyOf(a)] = true
+		if !before[keyOf(a)] {
+			started = append(started, a)
+		}
+	}
+	for _, a := range prev {
+		if !after[keyOf(a)] {
+			exited = append(exited, a)
+		}
+	}
+	return started, exited
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxBranchSlug caps the part of a worktree branch taken from the issue title.
+const maxBranchSlug = 40
+
+// worktreeLocks serializes EnsureIssueWorktree per checkout, so a queued
+// command and a pipeline step for the same issue do not both add its worktree.
+var worktreeLocks sync.Map
+
+func lockCheckout(checkout string) func() {
+	mu, _ := worktreeLocks.LoadOrStore(filepath.Clean(checkout), &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// Worktree is a linked git worktree of a checkout.
+type Worktree struct {
+	Dir    string
+	Branch string
+}
+
+// IssueBranch returns the branch of an issue's worktree, e.g.
+// "issue-42-cache-agents" for issue 42 titled "Cache agents".
+func IssueBranch(issue int, title string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if slug.Len() >= maxBranchSlug {
+			break
+		}
+	}
+	if slug.Len() == 0 {
+		return fmt.Sprintf("issue-%d", issue)
+	}
+	return fmt.Sprintf("issue-%d-%s", issue, slug.String())
+}
+
+// isIssueBranch reports whether branch belongs to issue, whatever the title
+// was when it was created.
+func isIssueBranch(branch string, issue int) bool {
+	prefix := fmt.Sprintf("issue-%d", issue)
+	return branch == prefix || strings.HasPrefix(branch, prefix+"-")
+}
+
+// WorktreesDir returns the directory holding the worktrees of checkout, next
+// to it: ~/repos/ai.worktrees for ~/repos/ai.
+func WorktreesDir(checkout string) string {
+	return filepath.Clean(checkout) + ".worktrees"
+}
+
+// ParseWorktreeList parses git worktree list --porcelain output. Detached
+// worktrees have no branch.
+func ParseWorktreeList(output string) []Worktree {
+	var worktrees []Worktree
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{Dir: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch ") && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+	return worktrees
+}
+
+// FindIssueWorktree returns the worktree of checkout on a branch of issue.
+func FindIssueWorktree(checkout string, issue int) (Worktree, bool, error) {
+	out, err := exec.Command("git", "-C", checkout, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return Worktree{}, false, fmt.Errorf("git worktree list in %s: %w", checkout, err)
+	}
+	// The first entry is the checkout itself
+	for i, wt := range ParseWorktreeList(string(out)) {
+		if i > 0 && isIssueBranch(wt.Branch, issue) {
+			return wt, true, nil
+		}
+	}
+	return Worktree{}, false, nil
+}
+
+// EnsureIssueWorktree returns the worktree of issue, creating it in
+// WorktreesDir on the branch IssueBranch when the issue has none. An existing
+// branch of that name is checked out rather than created.
+func EnsureIssueWorktree(checkout string, issue int, title string) (Worktree, error) {
+	defer lockCheckout(checkout)()
+	if wt, ok, err := FindIssueWorktree(checkout, issue); err != nil || ok {
+		return wt, err
+	}
+
+	branch := IssueBranch(issue, title)
+	wt := Worktree{Dir: filepath.Join(WorktreesDir(checkout), branch), Branch: branch}
+	// Forget worktrees whose directory was deleted by hand
+	_ = exec.Command("git", "-C", checkout, "worktree", "prune").Run()
+
+	args := []string{"-C", checkout, "worktree", "add", "-b", branch, wt.Dir}
+	if exec.Command("git", "-C", checkout, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+		args = []string{"-C", checkout, "worktree", "add", wt.Dir, branch}
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		// Another process may have added the issue's worktree meanwhile
+		if existing, ok, findErr := FindIssueWorktree(checkout, issue); findErr == nil && ok {
+			return existing, nil
+		}
+		return Worktree{}, fmt.Errorf("git worktree add %s: %w: %s", branch, err, strings.TrimSpace(string(out)))
+	}
+	return wt, nil
+}
+
+// RemoveWorktree removes the worktree at dir and keeps its branch. Git
+// refuses when the worktree has uncommitted changes.
+func RemoveWorktree(checkout, dir string) error {
+	if out, err := exec.Command("git", "-C", checkout, "worktree", "remove", dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree remove %s: %w: %s", dir, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Command is one entry of the command dialog.
+type Command struct {
+	Label string `yaml:"label"`
+	// Prompt is sent to the agent after replacing {number}, {title}, {repo}
+	// and {labels} with the selected issue's values.
+	Prompt string `yaml:"prompt"`
+	// Model overrides the model of the agent for this command.
+	Model string `yaml:"model"`
+	// Agent names an entry of Config.Agents; empty uses Config.Agent.
+	Agent string `yaml:"agent"`
+	// Phase is set on the issue when the command launches.
+	Phase string `yaml:"phase"`
+	// Advance moves the issue on to the first next phase of Phase once the
+	// agent exits successfully.
+	Advance bool `yaml:"advance"`
+}
+
+// Pipeline is a chain of commands run one after the other on an issue.
+type Pipeline struct {
+	Name string `yaml:"name"`
+	// Steps are command names such as "/tdd", see Command.Name.
+	Steps []string `yaml:"steps"`
+}
+
+// IssueVars are the values substituted into a command's prompt.
+type IssueVars struct {
+	Number int
+	Title  string
+	Repo   string
+	Labels []string
+}
+
+// DefaultCommands are the commands offered when the file defines none.
+func DefaultCommands() []Command {
+	return []Command{
+		{Label: "Skriv tester", Prompt: "/tdd {number}", Phase: "tester"},
+		{Label: "Implementera", Prompt: "/implement {number}", Phase: "implementation"},
+		{Label: "Refactor", Prompt: "/refactor {number}", Phase: "refactor"},
+		{Label: "Dokumentera", Prompt: "/docs {number}", Phase: "docs"},
+		{Label: "Skapa PR", Prompt: "/pr {number}", Phase: "pr"},
+	}
+}
+
+// DefaultPipelines are the pipelines offered when the file defines none.
+func DefaultPipelines() []Pipeline {
+	return []Pipeline{
+		{Name: "Hela flödet", Steps: []string{"/tdd", "/implement", "/refactor", "/docs", "/pr"}},
+	}
+}
+
+// Render returns the prompt with the placeholders filled in from v.
+func (c Command) Render(v IssueVars) string {
+	return strings.NewReplacer(
+		"{number}", strconv.Itoa(v.Number),
+		"{title}", v.Title,
+		"{repo}", v.Repo,
+		"{labels}", strings.Join(v.Labels, ","),
+	).Replace(c.Prompt)
+}
+
+// Name identifies the command in tmux window names, history, the queue and
+// pipelines, so no two commands may share it: the slash command its prompt
+// starts with, else its label in lower case with spaces replaced by dashes,
+// prefixed with a slash.
+func (c Command) Name() string {
+	if fields := strings.Fields(c.Prompt); len(fields) > 0 && strings.HasPrefix(fields[0], "/") {
+		return fields[0]
+	}
+	return "/" + strings.ToLower(strings.Join(strings.Fields(c.Label), "-"))
+}
+
+// CommandNamed returns the command whose Name is name.
+func (c Config) CommandNamed(name string) (Command, bool) {
+	for _, cmd := range c.Commands {
+		if cmd.Name() == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// AgentFor returns the agent that runs cmd, with the command's model
+// override applied.
+func (c Config) AgentFor(cmd Command) Agent {
+	a := c.Agent
+	if named, ok := c.Agents[cmd.Agent]; ok {
+		a = named
+	}
+	if cmd.Model != "" {
+		a.Model = cmd.Model
+	}
+	return a
+}
+
+// WindowPrefix is the agent's name in tmux window names: the binary's base
+// name up to the first dash or dot, so "opencode-secure" becomes "opencode".
+func (a Agent) WindowPrefix() string {
+	name := filepath.Base(a.Binary)
+	if i := strings.IndexAny(name, "-."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,409 @@
+// Package config loads the ai-tui configuration file from
+// $XDG_CONFIG_HOME/ai-tui/config.yaml, for example:
+//
+//	agent:
+//	  binary: ~/bin/opencode-secure
+//	  model: opencode/minimax-m2.5-free
+//	agents:
+//	  claude:
+//	    binary: claude
+//	    prompt_flag: ""
+//	commands:
+//	  - label: Skriv tester
+//	    prompt: /tdd {number}
+//	    phase: tester
+//	    advance: true
+//	  - label: Granska
+//	    prompt: "Review the fix for {repo}#{number}: {title}"
+//	    agent: claude
+//	    model: opus
+//	pipelines:
+//	  - name: Test first
+//	    steps: [/tdd, /implement]
+//	limits:
+//	  max_agents: 4
+//	  max_per_repo: 2
+//	  repos:
+//	    acme/api: 1
+//	phases:
+//	  - label: tester
+//	    next: [implementation]
+//	  - label: implementation
+//	    color: 0e8a16
+//	    description: Being implemented
+//	workflows:
+//	  acme/api:
+//	    - {label: spike, next: [design]}
+//	    - {label: design, next: [build]}
+//	    - {label: build, next: [review]}
+//	    - {label: review, next: [build]}
+//	local:
+//	  roots: [~/repos, ~/work]
+//	  worktrees: true
+//	  paths:
+//	    acme/api: ~/work/acme-api
+//	github:
+//	  owners: [simonbrundin]
+//	  orgs: [acme]
+//	  repos: [friend/tools]
+//	  exclude: ["acme/legacy-*"]
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the contents of the configuration file.
+type Config struct {
+	Agent Agent `yaml:"agent"`
+	// Agents are further agents that commands can select by name.
+	Agents   map[string]Agent `yaml:"agents"`
+	Commands []Command        `yaml:"commands"`
+	// Pipelines chain commands; each step starts when the previous agent
+	// exits successfully.
+	Pipelines []Pipeline `yaml:"pipelines"`
+	// Limits caps the agents running at once; commands beyond them wait in
+	// the queue.
+	Limits Limits `yaml:"limits"`
+	// Phases is the workflow of repos without an entry in Workflows.
+	Phases Workflow `yaml:"phases"`
+	// Workflows maps "owner/name" to the phases of that repo.
+	Workflows map[string]Workflow `yaml:"workflows"`
+	Local     Local               `yaml:"local"`
+	GitHub    GitHub              `yaml:"github"`
+}
+
+// Agent is the coding agent launched for issues.
+type Agent struct {
+	// Binary is the agent executable, looked up on PATH unless it is a path.
+	Binary string `yaml:"binary"`
+	// Model is passed with --model; empty leaves the agent's own default.
+	Model string `yaml:"model"`
+	// PromptFlag precedes the prompt, "--prompt" when unset. An explicit
+	// empty string passes the prompt as a positional argument.
+	PromptFlag *string `yaml:"prompt_flag"`
+}
+
+// Limits caps the number of agents running at once. Zero means no limit.
+type Limits struct {
+	// MaxAgents counts every running agent.
+	MaxAgents int `yaml:"max_agents"`
+	// MaxPerRepo counts the agents of each repository.
+	MaxPerRepo int `yaml:"max_per_repo"`
+	// Repos maps "owner/name" to a limit replacing MaxPerRepo.
+	Repos map[string]int `yaml:"repos"`
+}
+
+// Local says where repositories are checked out.
+type Local struct {
+	// Roots are directories holding checkouts named after the repository,
+	// e.g. ~/repos/ai for simonbrundin/ai. The first root with a match wins.
+	Roots []string `yaml:"roots"`
+	// Paths maps "owner/name" to its checkout, overriding Roots.
+	Paths map[string]string `yaml:"paths"`
+	// Worktrees runs each issue's agents in a git worktree of the checkout,
+	// on a branch such as issue-42-cache-agents, so agents on different
+	// issues of a repo do not share files.
+	Worktrees bool `yaml:"worktrees"`
+}
+
+// GitHub selects the repositories whose issues are shown.
+type GitHub struct {
+	// Owners are users whose repositories are searched.
+	Owners []string `yaml:"owners"`
+	// Orgs are organizations whose repositories are searched.
+	Orgs []string `yaml:"orgs"`
+	// Repos are individual "owner/name" repositories to search.
+	Repos []string `yaml:"repos"`
+	// Exclude lists glob patterns such as "simonbrundin/archive-*" for
+	// repositories to hide, matched case-insensitively against "owner/name".
+	Exclude []string `yaml:"exclude"`
+}
+
+// Default is the configuration used when no file exists.
+func Default() Config {
+	return Config{
+		Agent:     Agent{Binary: "opencode"},
+		Commands:  DefaultCommands(),
+		Pipelines: DefaultPipelines(),
+		Phases:    DefaultWorkflow().withDefaults(),
+		Local:     Local{Roots: []string{"~/repos"}},
+		GitHub:    GitHub{Owners: []string{"simonbrundin"}},
+	}
+}
+
+// WithDefaults fills settings left empty with those of Default. GitHub
+// owners only default when no owner, org or repo is set at all.
+func (c Config) WithDefaults() Config {
+	def := Default()
+	if c.Agent.Binary == "" {
+		c.Agent.Binary = def.Agent.Binary
+	}
+	if len(c.Phases) == 0 {
+		c.Phases = def.Phases
+	}
+	c.Phases = c.Phases.withDefaults()
+	if len(c.Workflows) > 0 {
+		workflows := make(map[string]Workflow, len(c.Workflows))
+		for repo, w := range c.Workflows {
+			workflows[repo] = w.withDefaults()
+		}
+		c.Workflows = workflows
+	}
+	if len(c.Commands) == 0 {
+		// Default commands only set phases the workflows know about
+		for _, cmd := range def.Commands {
+			if !c.hasPhase(cmd.Phase) {
+				cmd.Phase = ""
+			}
+			c.Commands = append(c.Commands, cmd)
+		}
+	}
+	if len(c.Pipelines) == 0 {
+		// Default pipelines are only offered when every step is a command
+		for _, p := range def.Pipelines {
+			if c.hasCommands(p.Steps) {
+				c.Pipelines = append(c.Pipelines, p)
+			}
+		}
+	}
+	if len(c.Local.Roots) == 0 {
+		c.Local.Roots = def.Local.Roots
+	}
+	if !c.GitHub.HasSources() {
+		c.GitHub.Owners = def.GitHub.Owners
+	}
+	return c
+}
+
+// Dir returns $XDG_CONFIG_HOME/ai-tui, falling back to ~/.config.
+func Dir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ai-tui")
+}
+
+// DefaultPath returns the configuration file in Dir.
+func DefaultPath() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+// Load reads the configuration file at path. A missing file yields Default.
+// Settings left out of the file keep their defaults.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cfg = cfg.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports malformed settings.
+func (c Config) Validate() error {
+	for _, pattern := range c.GitHub.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("github.exclude: bad pattern %q: %w", pattern, err)
+		}
+	}
+	for _, repo := range c.GitHub.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("github.repos: %q is not owner/name", repo)
+		}
+	}
+	names := make(map[string]int)
+	for i, cmd := range c.Commands {
+		if cmd.Label == "" || cmd.Prompt == "" {
+			return fmt.Errorf("commands[%d]: label and prompt are required", i)
+		}
+		// Queued jobs, pipelines and finished agents find commands by name
+		if j, ok := names[cmd.Name()]; ok {
+			return fmt.Errorf("commands[%d]: %s is already the name of commands[%d]", i, cmd.Name(), j)
+		}
+		names[cmd.Name()] = i
+		if _, ok := c.Agents[cmd.Agent]; cmd.Agent != "" && !ok {
+			return fmt.Errorf("commands[%d]: unknown agent %q", i, cmd.Agent)
+		}
+		if cmd.Phase != "" && !c.hasPhase(cmd.Phase) {
+			return fmt.Errorf("commands[%d]: unknown phase %q", i, cmd.Phase)
+		}
+		if cmd.Advance && cmd.Phase == "" {
+			return fmt.Errorf("commands[%d]: advance needs a phase", i)
+		}
+	}
+	for i, p := range c.Pipelines {
+		if p.Name == "" || len(p.Steps) == 0 {
+			return fmt.Errorf("pipelines[%d]: name and steps are required", i)
+		}
+		for _, step := range p.Steps {
+			if _, ok := c.CommandNamed(step); !ok {
+				return fmt.Errorf("pipelines[%d]: unknown command %q", i, step)
+			}
+		}
+	}
+	if err := c.Phases.validate(); err != nil {
+		return fmt.Errorf("phases: %w", err)
+	}
+	for repo, w := range c.Workflows {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("workflows: %q is not owner/name", repo)
+		}
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("workflows.%s: %w", repo, err)
+		}
+	}
+	for name, a := range c.Agents {
+		if a.Binary == "" {
+			return fmt.Errorf("agents.%s: binary is required", name)
+		}
+	}
+	if c.Limits.MaxAgents < 0 || c.Limits.MaxPerRepo < 0 {
+		return fmt.Errorf("limits: must not be negative")
+	}
+	for repo, n := range c.Limits.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("limits.repos: %q is not owner/name", repo)
+		}
+		if n < 0 {
+			return fmt.Errorf("limits.repos.%s: must not be negative", repo)
+		}
+	}
+	for repo := range c.Local.Paths {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("local.paths: %q is not owner/name", repo)
+		}
+	}
+	return nil
+}
+
+// Command returns the argv that starts the agent with prompt.
+func (a Agent) Command(prompt string) []string {
+	argv := []string{ExpandHome(a.Binary)}
+	if a.Model != "" {
+		argv = append(argv, "--model", a.Model)
+	}
+	flag := "--prompt"
+	if a.PromptFlag != nil {
+		flag = *a.PromptFlag
+	}
+	if flag != "" {
+		argv = append(argv, flag)
+	}
+	return append(argv, prompt)
+}
+
+// Repo returns the limit on the agents of repo ("owner/name").
+func (l Limits) Repo(repo string) int {
+	if n, ok := l.Repos[repo]; ok {
+		return n
+	}
+	return l.MaxPerRepo
+}
+
+// RepoPath returns the checkout of repo ("owner/name"): its entry in Paths,
+// else the first root containing a directory with the repo's name, else that
+// directory under the first root.
+func (l Local) RepoPath(repo string) string {
+	if p, ok := l.Paths[repo]; ok {
+		return ExpandHome(p)
+	}
+	_, name, ok := strings.Cut(repo, "/")
+	if !ok || name == "" || len(l.Roots) == 0 {
+		return ""
+	}
+	for _, root := range l.Roots {
+		dir := filepath.Join(ExpandHome(root), name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return filepath.Join(ExpandHome(l.Roots[0]), name)
+}
+
+// ExpandHome replaces a leading "~" with the home directory.
+func ExpandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+// hasCommands reports whether every name is a command.
+func (c Config) hasCommands(names []string) bool {
+	for _, name := range names {
+		if _, ok := c.CommandNamed(name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPhase reports whether any workflow has a phase labelled label.
+func (c Config) hasPhase(label string) bool {
+	if c.Phases.IsPhase(label) {
+		return true
+	}
+	for _, w := range c.Workflows {
+		if w.IsPhase(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSources reports whether any owner, org or repo is configured.
+func (g GitHub) HasSources() bool {
+	return len(g.Owners)+len(g.Orgs)+len(g.Repos) > 0
+}
+
+// SearchQuery builds the issue search for the configured sources. GitHub
+// matches repeated user, org and repo qualifiers as alternatives.
+func (g GitHub) SearchQuery() string {
+	terms := []string{"is:open", "archived:false"}
+	for _, owner := range g.Owners {
+		terms = append(terms, "user:"+owner)
+	}
+	for _, org := range g.Orgs {
+		terms = append(terms, "org:"+org)
+	}
+	for _, repo := range g.Repos {
+		terms = append(terms, "repo:"+repo)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Excluded reports whether repo matches one of the exclusion globs.
+func (g GitHub) Excluded(repo string) bool {
+	repo = strings.ToLower(repo)
+	for _, pattern := range g.Exclude {
+		if ok, _ := path.Match(strings.ToLower(pattern), repo); ok {
+			return true
+		}
+	}
+	return false
+}
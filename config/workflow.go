@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPhaseColor is the color of phase labels that do not set one.
+const DefaultPhaseColor = "c5def5"
+
+// Phase is one step of a workflow, tracked on issues as a label.
+type Phase struct {
+	Label string `yaml:"label"`
+	Color string `yaml:"color"`
+	// Title is shown in the phase dialog; empty uses Description, then Label.
+	Title string `yaml:"title"`
+	// Description is given to the label created on GitHub.
+	Description string `yaml:"description"`
+	// Next lists the labels of the phases an issue may move on to. Once any
+	// phase of a workflow lists some, phases without any are final.
+	Next []string `yaml:"next"`
+}
+
+// Workflow is the ordered list of phases an issue moves through. A workflow
+// where no phase lists Next lets issues move between all of its phases.
+type Workflow []Phase
+
+// DefaultWorkflow is used for repos without a workflow of their own when the
+// file defines no global phases.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		{Label: "tester", Title: "Issue är i testfas", Description: "Issue is in test phase"},
+		{Label: "implementation", Title: "Issue är i implementationsfas", Description: "Issue is in implementation phase"},
+		{Label: "refactor", Title: "Issue är i refaktoringsfas", Description: "Issue is in refactor phase"},
+		{Label: "docs", Title: "Issue är i dokumentationsfas", Description: "Issue is in documentation phase"},
+		{Label: "user_test", Title: "Issue är i användartestfas", Description: "Issue is in user test phase"},
+		{Label: "pr", Title: "Issue är i PR-fas", Description: "Issue is in PR phase"},
+	}
+}
+
+// Workflow returns the phases of repo: its entry in Workflows, else Phases.
+func (c Config) Workflow(repo string) Workflow {
+	if w, ok := c.Workflows[repo]; ok {
+		return w
+	}
+	return c.Phases
+}
+
+// Phase returns the phase labelled label.
+func (w Workflow) Phase(label string) (Phase, bool) {
+	for _, p := range w {
+		if strings.EqualFold(p.Label, label) {
+			return p, true
+		}
+	}
+	return Phase{}, false
+}
+
+// Current returns the phase of an issue with labels, i.e. the first of its
+// labels that is a phase.
+func (w Workflow) Current(labels []string) (Phase, bool) {
+	for _, l := range labels {
+		if p, ok := w.Phase(l); ok {
+			return p, true
+		}
+	}
+	return Phase{}, false
+}
+
+// Transitions returns the phases an issue with labels may move to. An issue
+// without a phase, or in a workflow without transitions, may enter any of them.
+func (w Workflow) Transitions(labels []string) []Phase {
+	current, ok := w.Current(labels)
+	if !ok || !w.restricted() {
+		return append([]Phase(nil), w...)
+	}
+	var next []Phase
+	for _, label := range current.Next {
+		if p, ok := w.Phase(label); ok {
+			next = append(next, p)
+		}
+	}
+	return next
+}
+
+// After returns the phase that follows label: the first of its next phases,
+// or in a workflow without transitions the phase listed after it.
+func (w Workflow) After(label string) (Phase, bool) {
+	current, ok := w.Phase(label)
+	if !ok {
+		return Phase{}, false
+	}
+	if !w.restricted() {
+		for i, p := range w {
+			if p.Label == current.Label && i+1 < len(w) {
+				return w[i+1], true
+			}
+		}
+		return Phase{}, false
+	}
+	if len(current.Next) == 0 {
+		return Phase{}, false
+	}
+	return w.Phase(current.Next[0])
+}
+
+// restricted reports whether any phase lists the phases that may follow it.
+func (w Workflow) restricted() bool {
+	for _, p := range w {
+		if len(p.Next) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPhase reports whether label belongs to the workflow.
+func (w Workflow) IsPhase(label string) bool {
+	_, ok := w.Phase(label)
+	return ok
+}
+
+func (w Workflow) withDefaults() Workflow {
+	out := make(Workflow, len(w))
+	for i, p := range w {
+		if p.Color == "" {
+			p.Color = DefaultPhaseColor
+		}
+		out[i] = p
+	}
+	return out
+}
+
+func (w Workflow) validate() error {
+	seen := make(map[string]bool, len(w))
+	for i, p := range w {
+		if p.Label == "" {
+			return fmt.Errorf("phase %d: label is required", i+1)
+		}
+		key := strings.ToLower(p.Label)
+		if seen[key] {
+			return fmt.Errorf("duplicate phase %q", p.Label)
+		}
+		seen[key] = true
+		if !isHexColor(p.Color) {
+			return fmt.Errorf("phase %q: color %q is not six hex digits", p.Label, p.Color)
+		}
+	}
+	for _, p := range w {
+		for _, next := range p.Next {
+			if !seen[strings.ToLower(next)] {
+				return fmt.Errorf("phase %q: unknown next phase %q", p.Label, next)
+			}
+		}
+	}
+	return nil
+}
+
+func isHexColor(s string) bool {
+	if len(s) != 6 {
+		return false
+	}
+	for _, r := range strings.ToLower(s) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
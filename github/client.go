@@ -0,0 +1,284 @@
+// Package github is a small typed client for the parts of the GitHub REST
+// API the monitor uses: searching and editing issues, labels and repos.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the public GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// maxPerPage is the largest page size the REST API accepts.
+const maxPerPage = 100
+
+// maxSearchResults is how far the search API pages; later results are not
+// returned even when total_count is higher.
+const maxSearchResults = 1000
+
+// Client calls the GitHub REST API. BaseURL is configurable so tests can
+// point it at an httptest server.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewClient returns a client for the public API authenticating with token.
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Issue is an issue as shown in the monitor.
+type Issue struct {
+	Number int
+	Title  string
+	// State is "open" or "closed".
+	State  string
+	Labels []string
+	// Repo is the full "owner/name" of the repository.
+	Repo string
+}
+
+// Label is a repository label.
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// IssuePage is one page of search results.
+type IssuePage struct {
+	Issues []Issue
+	// Total is the number of matching issues. It can exceed the issues
+	// reachable by paging, since search stops after 1000 results.
+	Total int
+	// NextPage is the page to request next, zero on the last page.
+	NextPage int
+}
+
+// Repo is a repository visible to the authenticated user.
+type Repo struct {
+	FullName string `json:"full_name"`
+	Archived bool   `json:"archived"`
+}
+
+type issueJSON struct {
+	Number        int     `json:"number"`
+	Title         string  `json:"title"`
+	State         string  `json:"state"`
+	Labels        []Label `json:"labels"`
+	RepositoryURL string  `json:"repository_url"`
+}
+
+func (i issueJSON) toIssue() Issue {
+	labels := make([]string, len(i.Labels))
+	for n, l := range i.Labels {
+		labels[n] = l.Name
+	}
+	// repository_url is <base>/repos/<owner>/<name>
+	repo := i.RepositoryURL
+	if idx := strings.LastIndex(repo, "/repos/"); idx >= 0 {
+		repo = repo[idx+len("/repos/"):]
+	}
+	return Issue{Number: i.Number, Title: i.Title, State: i.State, Labels: labels, Repo: repo}
+}
+
+// SearchIssues returns one page, starting at 1, of the issues matching a
+// search query such as "owner:simonbrundin is:open". Pull requests are
+// excluded.
+func (c *Client) SearchIssues(query string, page int) (IssuePage, error) {
+	if page < 1 {
+		page = 1
+	}
+	q := url.Values{}
+	q.Set("q", query+" is:issue")
+	q.Set("per_page", strconv.Itoa(maxPerPage))
+	q.Set("page", strconv.Itoa(page))
+
+	var result struct {
+		TotalCount int         `json:"total_count"`
+		Items      []issueJSON `json:"items"`
+	}
+	if err := c.do(http.MethodGet, "/search/issues?"+q.Encode(), nil, &result); err != nil {
+		return IssuePage{}, err
+	}
+
+	issues := make([]Issue, 0, len(result.Items))
+	for _, item := range result.Items {
+		issues = append(issues, item.toIssue())
+	}
+	next := 0
+	reachable := min(result.TotalCount, maxSearchResults)
+	if len(result.Items) == maxPerPage && page*maxPerPage < reachable {
+		next = page + 1
+	}
+	return IssuePage{Issues: issues, Total: result.TotalCount, NextPage: next}, nil
+}
+
+// GetIssue returns a single issue.
+func (c *Client) GetIssue(repo string, number int) (Issue, error) {
+	var result issueJSON
+	if err := c.do(http.MethodGet, issuePath(repo, number), nil, &result); err != nil {
+		return Issue{}, err
+	}
+	issue := result.toIssue()
+	issue.Repo = repo
+	return issue, nil
+}
+
+// CloseIssue closes an issue. Closing an already closed issue succeeds.
+func (c *Client) CloseIssue(repo string, number int) error {
+	return c.do(http.MethodPatch, issuePath(repo, number), map[string]string{"state": "closed"}, nil)
+}
+
+// ReopenIssue reopens a closed issue.
+func (c *Client) ReopenIssue(repo string, number int) error {
+	return c.do(http.MethodPatch, issuePath(repo, number), map[string]string{"state": "open"}, nil)
+}
+
+// AddLabels adds labels to an issue, keeping its existing labels.
+func (c *Client) AddLabels(repo string, number int, labels ...string) error {
+	body := map[string][]string{"labels": labels}
+	return c.do(http.MethodPost, issuePath(repo, number)+"/labels", body, nil)
+}
+
+// RemoveLabel removes one label from an issue.
+func (c *Client) RemoveLabel(repo string, number int, label string) error {
+	return c.do(http.MethodDelete, issuePath(repo, number)+"/labels/"+url.PathEscape(label), nil, nil)
+}
+
+// ListLabels returns the labels of a repository, following pagination to the
+// end.
+func (c *Client) ListLabels(repo string) ([]Label, error) {
+	var all []Label
+	for page := 1; ; page++ {
+		var labels []Label
+		path := fmt.Sprintf("/repos/%s/labels?per_page=%d&page=%d", repo, maxPerPage, page)
+		if err := c.do(http.MethodGet, path, nil, &labels); err != nil {
+			return nil, err
+		}
+		all = append(all, labels...)
+		if len(labels) < maxPerPage {
+			return all, nil
+		}
+	}
+}
+
+// CreateLabel creates a label. It fails with ErrAlreadyExists when a label
+// with the same name exists.
+func (c *Client) CreateLabel(repo string, label Label) error {
+	return c.do(http.MethodPost, "/repos/"+repo+"/labels", label, nil)
+}
+
+// ListUserRepos returns every repository the authenticated user owns, has
+// access to through an organization or collaborates on, most recently updated
+// first, following pagination to the end.
+func (c *Client) ListUserRepos() ([]Repo, error) {
+	var all []Repo
+	for page := 1; ; page++ {
+		var repos []Repo
+		path := fmt.Sprintf("/user/repos?affiliation=owner,organization_member,collaborator&sort=updated&per_page=%d&page=%d", maxPerPage, page)
+		if err := c.do(http.MethodGet, path, nil, &repos); err != nil {
+			return nil, err
+		}
+		all = append(all, repos...)
+		if len(repos) < maxPerPage {
+			return all, nil
+		}
+	}
+}
+
+func issuePath(repo string, number int) string {
+	return fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out when out is non-nil.
+func (c *Client) do(method, path string, body, out any) error {
+	if c.Token == "" {
+		return ErrNotAuthenticated
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.BaseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return newAPIError(req, resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+	}
+	var body struct {
+		Message string       `json:"message"`
+		Errors  []FieldError `json:"errors"`
+	}
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024)); err == nil {
+		if json.Unmarshal(data, &body) == nil {
+			apiErr.Message, apiErr.Errors = body.Message, body.Errors
+		}
+	}
+
+	// Primary rate limits answer 403 with no remaining requests; secondary
+	// limits say so in the message.
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(apiErr.Message), "rate limit") {
+			apiErr.rateLimited = true
+		}
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			apiErr.RateLimitReset = time.Unix(reset, 0)
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,76 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for the failures the UI reacts to. Match them with
+// errors.Is; the concrete error is usually an *APIError.
+var (
+	ErrNotAuthenticated = errors.New("GitHub not authenticated. Run 'gh auth login' or set GITHUB_TOKEN")
+	ErrRateLimited      = errors.New("GitHub API rate limited. Please wait and try again")
+	ErrNotFound         = errors.New("not found on GitHub")
+	ErrAlreadyExists    = errors.New("already exists on GitHub")
+	ErrNetwork          = errors.New("Network error. Check your internet connection")
+)
+
+// APIError is a non-2xx response from the GitHub API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the "message" field of the response body.
+	Message string
+	// Errors are the validation errors of a 422 response.
+	Errors []FieldError
+	// RateLimitReset is when the rate limit resets, set on rate limit errors.
+	RateLimitReset time.Time
+	rateLimited    bool
+}
+
+// FieldError is one entry of the "errors" array of a validation failure.
+type FieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+}
+
+func (e *APIError) Error() string {
+	detail := fmt.Sprintf("%s %s: %d", e.Method, e.Path, e.StatusCode)
+	if e.Message != "" {
+		detail += " " + e.Message
+	}
+	if kind := e.kind(); kind != nil {
+		return fmt.Sprintf("%s (%s)", kind, detail)
+	}
+	return "GitHub API error: " + detail
+}
+
+// Is maps the response onto the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	return target != nil && e.kind() == target
+}
+
+func (e *APIError) kind() error {
+	switch {
+	case e.rateLimited || e.StatusCode == 429:
+		return ErrRateLimited
+	case e.StatusCode == 401:
+		return ErrNotAuthenticated
+	case e.StatusCode == 404:
+		return ErrNotFound
+	case e.StatusCode == 422:
+		for _, fe := range e.Errors {
+			if fe.Code == "already_exists" {
+				return ErrAlreadyExists
+			}
+		}
+		if strings.Contains(strings.ToLower(e.Message), "already exists") {
+			return ErrAlreadyExists
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory Tracker for driving the monitor offline in tests.
+// Unknown repos and issues fail like the API does, with an *APIError
+// matching ErrNotFound.
+type Fake struct {
+	// PageSize is the number of search results per page, 100 like the API
+	// by default.
+	PageSize int
+
+	mu     sync.Mutex
+	repos  []Repo
+	issues map[string]map[int]*Issue
+	labels map[string][]Label
+	fail   map[string]error
+	calls  []string
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		PageSize: maxPerPage,
+		issues:   make(map[string]map[int]*Issue),
+		labels:   make(map[string][]Label),
+		fail:     make(map[string]error),
+	}
+}
+
+// AddRepo adds a repository owned by the authenticated user.
+func (f *Fake) AddRepo(fullName string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addRepo(fullName)
+	return f
+}
+
+func (f *Fake) addRepo(fullName string) {
+	if _, ok := f.issues[fullName]; ok {
+		return
+	}
+	f.repos = append(f.repos, Repo{FullName: fullName})
+	f.issues[fullName] = make(map[int]*Issue)
+}
+
+// AddIssue adds an issue, creating its repo and any missing labels. An empty
+// state means open.
+func (f *Fake) AddIssue(issue Issue) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addRepo(issue.Repo)
+	if issue.State == "" {
+		issue.State = "open"
+	}
+	issue.Labels = append([]string{}, issue.Labels...)
+	for _, name := range issue.Labels {
+		if !f.hasLabel(issue.Repo, name) {
+			f.labels[issue.Repo] = append(f.labels[issue.Repo], Label{Name: name})
+		}
+	}
+	f.issues[issue.Repo][issue.Number] = &issue
+	return f
+}
+
+// Issue returns the current state of an issue.
+func (f *Fake) Issue(repo string, number int) (Issue, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	issue, ok := f.issues[repo][number]
+	if !ok {
+		return Issue{}, false
+	}
+	return copyIssue(issue), true
+}
+
+// FailOn makes every later call of the named method, e.g. "CloseIssue",
+// return err. A nil err clears the failure.
+func (f *Fake) FailOn(method string, err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.fail, method)
+	} else {
+		f.fail[method] = err
+	}
+	return f
+}
+
+// Calls returns the methods called so far, in order.
+func (f *Fake) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// SearchIssues understands the "user:", "org:", "repo:", "label:" and
+// "is:open" / "is:closed" qualifiers; other terms are ignored. Like GitHub,
+// repeated user, org and repo qualifiers match any of their values.
+func (f *Fake) SearchIssues(query string, page int) (IssuePage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("SearchIssues"); err != nil {
+		return IssuePage{}, err
+	}
+
+	var owners, repos, labels []string
+	state := ""
+	for _, term := range strings.Fields(query) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "owner", "user", "org":
+			owners = append(owners, value)
+		case "repo":
+			repos = append(repos, value)
+		case "label":
+			labels = append(labels, value)
+		case "is", "state":
+			if value == "open" || value == "closed" {
+				state = value
+			}
+		}
+	}
+
+	var result []Issue
+	for _, repo := range f.repos {
+		owner, _, _ := strings.Cut(repo.FullName, "/")
+		scoped := len(owners) > 0 || len(repos) > 0
+		if scoped && !slices.Contains(owners, owner) && !slices.Contains(repos, repo.FullName) {
+			continue
+		}
+		for _, issue := range f.sortedIssues(repo.FullName) {
+			if state != "" && issue.State != state {
+				continue
+			}
+			if !containsAll(issue.Labels, labels) {
+				continue
+			}
+			result = append(result, copyIssue(issue))
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	start := min((page-1)*f.PageSize, len(result))
+	end := min(start+f.PageSize, len(result))
+	next := 0
+	if end < len(result) {
+		next = page + 1
+	}
+	return IssuePage{Issues: result[start:end], Total: len(result), NextPage: next}, nil
+}
+
+func (f *Fake) GetIssue(repo string, number int) (Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GetIssue"); err != nil {
+		return Issue{}, err
+	}
+	issue, err := f.issue(repo, number)
+	if err != nil {
+		return Issue{}, err
+	}
+	return copyIssue(issue), nil
+}
+
+func (f *Fake) CloseIssue(repo string, number int) error {
+	return f.setState("CloseIssue", repo, number, "closed")
+}
+
+func (f *Fake) ReopenIssue(repo string, number int) error {
+	return f.setState("ReopenIssue", repo, number, "open")
+}
+
+func (f *Fake) setState(method, repo string, number int, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(method); err != nil {
+		return err
+	}
+	issue, err := f.issue(repo, number)
+	if err != nil {
+		return err
+	}
+	issue.State = state
+	return nil
+}
+
+// AddLabels creates labels that do not exist yet, as the API does.
+func (f *Fake) AddLabels(repo string, number int, labels ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AddLabels"); err != nil {
+		return err
+	}
+	issue, err := f.issue(repo, number)
+	if err != nil {
+		return err
+	}
+	for _, name := range labels {
+		if !f.hasLabel(repo, name) {
+			f.labels[repo] = append(f.labels[repo], Label{Name: name})
+		}
+		if !slices.Contains(issue.Labels, name) {
+			issue.Labels = append(issue.Labels, name)
+		}
+	}
+	return nil
+}
+
+func (f *Fake) RemoveLabel(repo string, number int, label string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveLabel"); err != nil {
+		return err
+	}
+	issue, err := f.issue(repo, number)
+	if err != nil {
+		return err
+	}
+	idx := slices.Index(issue.Labels, label)
+	if idx < 0 {
+		return f.notFound(http.MethodDelete, fmt.Sprintf("%s/labels/%s", issuePath(repo, number), label))
+	}
+	issue.Labels = slices.Delete(issue.Labels, idx, idx+1)
+	return nil
+}
+
+func (f *Fake) ListLabels(repo string) ([]Label, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ListLabels"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.issues[repo]; !ok {
+		return nil, f.notFound(http.MethodGet, "/repos/"+repo+"/labels")
+	}
+	return append([]Label(nil), f.labels[repo]...), nil
+}
+
+func (f *Fake) CreateLabel(repo string, label Label) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateLabel"); err != nil {
+		return err
+	}
+	if _, ok := f.issues[repo]; !ok {
+		return f.notFound(http.MethodPost, "/repos/"+repo+"/labels")
+	}
+	if f.hasLabel(repo, label.Name) {
+		return &APIError{
+			Method:     http.MethodPost,
+			Path:       "/repos/" + repo + "/labels",
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Validation Failed",
+			Errors:     []FieldError{{Resource: "Label", Field: "name", Code: "already_exists"}},
+		}
+	}
+	f.labels[repo] = append(f.labels[repo], label)
+	return nil
+}
+
+func (f *Fake) ListUserRepos() ([]Repo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ListUserRepos"); err != nil {
+		return nil, err
+	}
+	return append([]Repo(nil), f.repos...), nil
+}
+
+// call records a call to method and returns its configured failure.
+func (f *Fake) call(method string) error {
+	f.calls = append(f.calls, method)
+	return f.fail[method]
+}
+
+func (f *Fake) issue(repo string, number int) (*Issue, error) {
+	issue, ok := f.issues[repo][number]
+	if !ok {
+		return nil, f.notFound(http.MethodGet, issuePath(repo, number))
+	}
+	return issue, nil
+}
+
+func (f *Fake) hasLabel(repo, name string) bool {
+	for _, l := range f.labels[repo] {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fake) sortedIssues(repo string) []*Issue {
+	issues := make([]*Issue, 0, len(f.issues[repo]))
+	for _, issue := range f.issues[repo] {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Number < issues[j].Number })
+	return issues
+}
+
+func (f *Fake) notFound(method, path string) error {
+	return &APIError{Method: method, Path: path, StatusCode: http.StatusNotFound, Message: "Not Found"}
+}
+
+func copyIssue(issue *Issue) Issue {
+	c := *issue
+	c.Labels = append([]string{}, issue.Labels...)
+	return c
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Token returns the token to authenticate with: GH_TOKEN or GITHUB_TOKEN
+// when set, otherwise the token stored by `gh auth login`.
+func Token() (string, error) {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, nil
+		}
+	}
+	out, err := exec.Command("gh", "auth", "token").Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: gh auth token: %v", ErrNotAuthenticated, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", ErrNotAuthenticated
+	}
+	return token, nil
+}
@@ -0,0 +1,20 @@
+package github
+
+// Tracker is the issue tracker the monitor works against. Client implements
+// it over the REST API and Fake in memory.
+type Tracker interface {
+	SearchIssues(query string, page int) (IssuePage, error)
+	GetIssue(repo string, number int) (Issue, error)
+	CloseIssue(repo string, number int) error
+	ReopenIssue(repo string, number int) error
+	AddLabels(repo string, number int, labels ...string) error
+	RemoveLabel(repo string, number int, label string) error
+	ListLabels(repo string) ([]Label, error)
+	CreateLabel(repo string, label Label) error
+	ListUserRepos() ([]Repo, error)
+}
+
+var (
+	_ Tracker = (*Client)(nil)
+	_ Tracker = (*Fake)(nil)
+)
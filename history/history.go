@@ -0,0 +1,375 @@
+// Package history records agent lifecycle events to a local JSON lines file
+// and folds them into sessions for the Timeline tab.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"ai-tui/agent"
+)
+
+// EventType is the kind of lifecycle change an Event records.
+type EventType string
+
+const (
+	EventStarted EventType = "started"
+	EventBusy    EventType = "busy"
+	EventIdle    EventType = "idle"
+	EventWaiting EventType = "waiting"
+	EventExited  EventType = "exited"
+)
+
+const (
+	// DefaultMaxEvents is how many of the newest events a Recorder keeps.
+	DefaultMaxEvents = 20000
+	// DefaultMaxAge is how long a Recorder keeps events.
+	DefaultMaxAge = 90 * 24 * time.Hour
+)
+
+// Event is one line of the history file.
+type Event struct {
+	Time       time.Time     `json:"time"`
+	Type       EventType     `json:"type"`
+	Kind       string        `json:"kind"`
+	PID        int           `json:"pid"`
+	StartTime  time.Time     `json:"start_time,omitempty"`
+	WorkingDir string        `json:"working_dir,omitempty"`
+	Repo       string        `json:"repo,omitempty"`
+	Issue      int           `json:"issue,omitempty"`
+	Command    string        `json:"command,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+}
+
+// DefaultPath returns $XDG_STATE_HOME/ai-tui/history.jsonl, falling back to
+// ~/.local/state when XDG_STATE_HOME is unset.
+func DefaultPath() string {
+	return filepath.Join(StateDir(), "history.jsonl")
+}
+
+// StateDir returns the ai-tui directory under XDG state.
+func StateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "ai-tui")
+}
+
+// Load reads every event in the file at path. A missing file is empty.
+func Load(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		// Skip lines torn by a crash mid-write rather than losing the history.
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// Recorder diffs successive agent snapshots and appends the resulting events
+// to the history file. It keeps the newest maxEvents events no older than
+// maxAge: older events are dropped from the file when it is loaded, and the
+// file is rewritten once it holds twice as many events as are kept.
+type Recorder struct {
+	mu        sync.Mutex
+	path      string
+	now       func() time.Time
+	maxEvents int
+	maxAge    time.Duration
+	loaded    bool
+	events    []Event
+	// written is the number of events in the file
+	written int
+	open    map[string]agent.Agent
+}
+
+func NewRecorder(path string) *Recorder {
+	return &Recorder{
+		path:      path,
+		now:       time.Now,
+		maxEvents: DefaultMaxEvents,
+		maxAge:    DefaultMaxAge,
+		open:      make(map[string]agent.Agent),
+	}
+}
+
+// SetClock replaces the clock used to timestamp events.
+func (r *Recorder) SetClock(now func() time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = now
+}
+
+// SetLimits replaces how many events are kept and for how long.
+func (r *Recorder) SetLimits(maxEvents int, maxAge time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxEvents, r.maxAge = maxEvents, maxAge
+}
+
+// MaxEvents returns how many of the newest events are kept.
+func (r *Recorder) MaxEvents() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.maxEvents
+}
+
+// sessionKey identifies an agent process across snapshots; the start time
+// guards against PID reuse.
+func sessionKey(pid int, start time.Time) string {
+	return fmt.Sprintf("%d@%d", pid, start.Unix())
+}
+
+// Observe records the changes between the previous snapshot and agents and
+// returns the new events. On first use it loads the existing file so agents
+// that were already running are not reported as started again.
+func (r *Recorder) Observe(agents []agent.Agent) ([]Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	var added []Event
+	seen := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		key := sessionKey(a.PID, a.StartTime)
+		seen[key] = true
+		prev, known := r.open[key]
+		switch {
+		case !known:
+			added = append(added, newEvent(now, EventStarted, a))
+			if t, ok := stateEvent(a.State); ok {
+				added = append(added, newEvent(now, t, a))
+			}
+		case prev.State != a.State:
+			if t, ok := stateEvent(a.State); ok {
+				added = append(added, newEvent(now, t, a))
+			}
+		}
+		if known {
+			a = mergeLink(a, prev)
+		}
+		r.open[key] = a
+	}
+
+	var gone []string
+	for key := range r.open {
+		if !seen[key] {
+			gone = append(gone, key)
+		}
+	}
+	sort.Strings(gone)
+	for _, key := range gone {
+		a := r.open[key]
+		e := newEvent(now, EventExited, a)
+		if !a.StartTime.IsZero() {
+			e.Duration = now.Sub(a.StartTime)
+		}
+		added = append(added, e)
+		delete(r.open, key)
+	}
+
+	if err := r.append(added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Events returns the kept events, including those loaded from disk.
+func (r *Recorder) Events() ([]Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return append([]Event(nil), r.events...), nil
+}
+
+func (r *Recorder) load() error {
+	if r.loaded {
+		return nil
+	}
+	events, err := Load(r.path)
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	r.written = len(events)
+	r.events = r.retain(events)
+	// Agents still running are found among every event, pruned or not
+	for _, e := range events {
+		key := sessionKey(e.PID, e.StartTime)
+		if e.Type == EventExited {
+			delete(r.open, key)
+			continue
+		}
+		prev := r.open[key]
+		a := mergeLink(agent.Agent{
+			Name:       e.Kind,
+			PID:        e.PID,
+			StartTime:  e.StartTime,
+			WorkingDir: e.WorkingDir,
+			Repo:       e.Repo,
+			Issue:      e.Issue,
+			Command:    e.Command,
+			State:      prev.State,
+		}, prev)
+		if s, ok := eventState(e.Type); ok {
+			a.State = s
+		}
+		r.open[key] = a
+	}
+	r.loaded = true
+	if len(r.events) < r.written {
+		return r.rewrite()
+	}
+	return nil
+}
+
+// retain returns the newest events within the limits. Events are in time
+// order, so the kept ones are a suffix.
+func (r *Recorder) retain(events []Event) []Event {
+	first := 0
+	if r.maxEvents > 0 && len(events) > r.maxEvents {
+		first = len(events) - r.maxEvents
+	}
+	if r.maxAge > 0 {
+		cutoff := r.now().Add(-r.maxAge)
+		for first < len(events) && events[first].Time.Before(cutoff) {
+			first++
+		}
+	}
+	return events[first:]
+}
+
+// rewrite replaces the file with the kept events.
+func (r *Recorder) rewrite() error {
+	r.events = append([]Event(nil), r.events...)
+	tmp := r.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("rewrite history: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range r.events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return fmt.Errorf("rewrite history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("rewrite history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("rewrite history: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("rewrite history: %w", err)
+	}
+	r.written = len(r.events)
+	return nil
+}
+
+func (r *Recorder) append(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("write history: %w", err)
+		}
+		r.events = append(r.events, e)
+		r.written++
+	}
+	r.events = r.retain(r.events)
+	if r.maxEvents > 0 && r.written > 2*r.maxEvents {
+		return r.rewrite()
+	}
+	return nil
+}
+
+// mergeLink keeps the issue link of prev when a lacks one, since panes and
+// prompts are not always readable on every scan.
+func mergeLink(a, prev agent.Agent) agent.Agent {
+	if a.Repo == "" {
+		a.Repo = prev.Repo
+	}
+	if a.Issue == 0 {
+		a.Issue, a.Command = prev.Issue, prev.Command
+	}
+	return a
+}
+
+func newEvent(now time.Time, t EventType, a agent.Agent) Event {
+	return Event{
+		Time:       now,
+		Type:       t,
+		Kind:       a.Name,
+		PID:        a.PID,
+		StartTime:  a.StartTime,
+		WorkingDir: a.WorkingDir,
+		Repo:       a.Repo,
+		Issue:      a.Issue,
+		Command:    a.Command,
+	}
+}
+
+func stateEvent(s agent.State) (EventType, bool) {
+	switch s {
+	case agent.StateBusy:
+		return EventBusy, true
+	case agent.StateIdle:
+		return EventIdle, true
+	case agent.StateWaiting:
+		return EventWaiting, true
+	}
+	return "", false
+}
+
+func eventState(t EventType) (agent.State, bool) {
+	switch t {
+	case EventBusy:
+		return agent.StateBusy, true
+	case EventIdle:
+		return agent.StateIdle, true
+	case EventWaiting:
+		return agent.StateWaiting, true
+	}
+	return agent.StateUnknown, false
+}
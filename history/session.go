@@ -0,0 +1,145 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Session is one agent run folded from its events.
+type Session struct {
+	Kind       string
+	PID        int
+	WorkingDir string
+	Repo       string
+	Issue      int
+	Command    string
+	Start      time.Time
+	// End is zero while the agent is still running.
+	End time.Time
+	// Busy is the time the agent spent working rather than idle or waiting.
+	Busy time.Duration
+}
+
+// Running reports whether no exit has been recorded for the session.
+func (s Session) Running() bool {
+	return s.End.IsZero()
+}
+
+// Duration is the wall-clock length of the session, measured up to now for
+// running sessions.
+func (s Session) Duration(now time.Time) time.Duration {
+	end := s.End
+	if end.IsZero() {
+		end = now
+	}
+	if end.Before(s.Start) {
+		return 0
+	}
+	return end.Sub(s.Start)
+}
+
+type sessionState struct {
+	Session
+	busySince time.Time
+}
+
+// Sessions folds events into sessions ordered by start time, newest first.
+// Busy time of running sessions is counted up to now.
+func Sessions(events []Event, now time.Time) []Session {
+	open := make(map[string]*sessionState)
+	var sessions []Session
+
+	for _, e := range events {
+		key := sessionKey(e.PID, e.StartTime)
+		s, ok := open[key]
+		if !ok {
+			start := e.StartTime
+			if start.IsZero() {
+				start = e.Time
+			}
+			s = &sessionState{Session: Session{Kind: e.Kind, PID: e.PID, Start: start}}
+			open[key] = s
+		}
+		if e.WorkingDir != "" {
+			s.WorkingDir = e.WorkingDir
+		}
+		if e.Repo != "" {
+			s.Repo = e.Repo
+		}
+		if e.Issue != 0 {
+			s.Issue, s.Command = e.Issue, e.Command
+		}
+
+		if !s.busySince.IsZero() && e.Type != EventBusy {
+			s.Busy += e.Time.Sub(s.busySince)
+			s.busySince = time.Time{}
+		}
+		switch e.Type {
+		case EventBusy:
+			if s.busySince.IsZero() {
+				s.busySince = e.Time
+			}
+		case EventExited:
+			s.End = e.Time
+			sessions = append(sessions, s.Session)
+			delete(open, key)
+		}
+	}
+
+	for _, s := range open {
+		if !s.busySince.IsZero() {
+			s.Busy += now.Sub(s.busySince)
+		}
+		sessions = append(sessions, s.Session)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Start.After(sessions[j].Start)
+	})
+	return sessions
+}
+
+// IssueTotal is the time spent by all sessions linked to one issue.
+type IssueTotal struct {
+	Repo     string
+	Issue    int
+	Sessions int
+	Duration time.Duration
+	Busy     time.Duration
+}
+
+// TotalsByIssue sums sessions per repo and issue, ordered by repo and then
+// by issue number. Sessions without an issue link are left out.
+func TotalsByIssue(sessions []Session, now time.Time) []IssueTotal {
+	type key struct {
+		repo  string
+		issue int
+	}
+	totals := make(map[key]*IssueTotal)
+	for _, s := range sessions {
+		if s.Issue == 0 {
+			continue
+		}
+		k := key{s.Repo, s.Issue}
+		t, ok := totals[k]
+		if !ok {
+			t = &IssueTotal{Repo: s.Repo, Issue: s.Issue}
+			totals[k] = t
+		}
+		t.Sessions++
+		t.Duration += s.Duration(now)
+		t.Busy += s.Busy
+	}
+
+	result := make([]IssueTotal, 0, len(totals))
+	for _, t := range totals {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Repo != result[j].Repo {
+			return result[i].Repo < result[j].Repo
+		}
+		return result[i].Issue < result[j].Issue
+	})
+	return result
+}
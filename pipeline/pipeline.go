@@ -0,0 +1,223 @@
+// Package pipeline keeps track of per-issue chains of agent commands, such as
+// /tdd → /implement → /pr, in a JSON file so runs survive restarts.
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the state of a Run.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+var (
+	// ErrRunning is returned when starting a pipeline on an issue that
+	// already has one running.
+	ErrRunning = errors.New("a pipeline is already running for this issue")
+	// ErrNoRun is returned for issues without a pipeline.
+	ErrNoRun = errors.New("no pipeline for this issue")
+)
+
+// Run is the progress of one pipeline on one issue.
+type Run struct {
+	Repo  string   `json:"repo"`
+	Issue int      `json:"issue"`
+	Name  string   `json:"name"`
+	Steps []string `json:"steps"`
+	// Step is the index of the step being run, or len(Steps) once done.
+	Step    int       `json:"step"`
+	Status  Status    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// Command returns the command of the current step, or "" once done.
+func (r Run) Command() string {
+	if r.Step < 0 || r.Step >= len(r.Steps) {
+		return ""
+	}
+	return r.Steps[r.Step]
+}
+
+// Key identifies the issue of the run.
+func (r Run) Key() string {
+	return Key(r.Repo, r.Issue)
+}
+
+// Key identifies an issue as "owner/name#number".
+func Key(repo string, issue int) string {
+	return fmt.Sprintf("%s#%d", repo, issue)
+}
+
+// Store holds the runs and writes them to a file after every change.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	now    func() time.Time
+	loaded bool
+	runs   map[string]Run
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path, now: time.Now, runs: make(map[string]Run)}
+}
+
+// SetClock replaces the clock used to timestamp changes.
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Runs returns every run ordered by repo and issue.
+func (s *Store) Runs() ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	runs := make([]Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].Repo != runs[j].Repo {
+			return runs[i].Repo < runs[j].Repo
+		}
+		return runs[i].Issue < runs[j].Issue
+	})
+	return runs, nil
+}
+
+// Start begins the pipeline name on an issue. A failed run of the same
+// pipeline resumes at the step that failed; anything else starts over.
+func (s *Store) Start(repo string, issue int, name string, steps []string) (Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Run{}, err
+	}
+	if len(steps) == 0 {
+		return Run{}, fmt.Errorf("pipeline %q has no steps", name)
+	}
+
+	key := Key(repo, issue)
+	run, ok := s.runs[key]
+	switch {
+	case ok && run.Status == StatusRunning:
+		return run, ErrRunning
+	case ok && run.Status == StatusFailed && run.Name == name:
+		run.Status, run.Error = StatusRunning, ""
+	default:
+		run = Run{Repo: repo, Issue: issue, Name: name, Steps: append([]string(nil), steps...), Status: StatusRunning}
+	}
+	return s.put(run)
+}
+
+// Advance marks the current step of an issue's running pipeline as done and
+// moves on to the next one, finishing the run after the last step.
+func (s *Store) Advance(repo string, issue int) (Run, error) {
+	return s.update(repo, issue, func(r *Run) {
+		r.Step++
+		if r.Step >= len(r.Steps) {
+			r.Status = StatusDone
+		}
+	})
+}
+
+// Fail stops an issue's running pipeline at its current step.
+func (s *Store) Fail(repo string, issue int, reason string) (Run, error) {
+	return s.update(repo, issue, func(r *Run) {
+		r.Status, r.Error = StatusFailed, reason
+	})
+}
+
+// Remove forgets the pipeline of an issue.
+func (s *Store) Remove(repo string, issue int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	delete(s.runs, Key(repo, issue))
+	return s.save()
+}
+
+func (s *Store) update(repo string, issue int, change func(*Run)) (Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Run{}, err
+	}
+	run, ok := s.runs[Key(repo, issue)]
+	if !ok || run.Status != StatusRunning {
+		return run, ErrNoRun
+	}
+	change(&run)
+	return s.put(run)
+}
+
+func (s *Store) put(run Run) (Run, error) {
+	run.Updated = s.now()
+	s.runs[run.Key()] = run
+	return run, s.save()
+}
+
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("load pipelines: %w", err)
+	}
+	if len(data) > 0 {
+		var runs []Run
+		if err := json.Unmarshal(data, &runs); err != nil {
+			return fmt.Errorf("parse %s: %w", s.path, err)
+		}
+		for _, r := range runs {
+			s.runs[r.Key()] = r
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// save writes the runs to a temporary file and renames it over the old one,
+// so a crash never leaves a half-written file behind.
+func (s *Store) save() error {
+	runs := make([]Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Key() < runs[j].Key() })
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create pipeline dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write pipelines: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write pipelines: %w", err)
+	}
+	return nil
+}
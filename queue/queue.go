@@ -0,0 +1,262 @@
+// Package queue holds agent commands that wait for a free slot under the
+// configured limits on concurrent agents, and starts them in order as
+// running agents exit.
+package queue
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ai-tui/agent"
+	"ai-tui/config"
+)
+
+// StartTimeout is how long a started job keeps counting against the limits
+// while its agent has not been detected yet.
+const StartTimeout = time.Minute
+
+var (
+	// ErrNoJob is returned for jobs that are not in the queue.
+	ErrNoJob = errors.New("no such job in the queue")
+	// ErrStarted is returned when changing a job that already started.
+	ErrStarted = errors.New("job already started")
+)
+
+// Job is a command to run on an issue.
+type Job struct {
+	ID    int
+	Repo  string
+	Issue int
+	Title string
+	// Command is the name of the command, e.g. "/tdd".
+	Command string
+	// Step marks the current step of the issue's pipeline.
+	Step   bool
+	Queued time.Time
+	// Started is set once the job was handed out to be launched.
+	Started time.Time
+}
+
+// Waiting reports whether the job has not started yet.
+func (j Job) Waiting() bool {
+	return j.Started.IsZero()
+}
+
+// runs reports whether a is the agent of the job.
+func (j Job) runs(a agent.Agent) bool {
+	return a.Repo == j.Repo && a.Issue == j.Issue && a.Command == j.Command
+}
+
+// Queue orders the waiting jobs and remembers started jobs until their agent
+// shows up, so a launch in progress still counts against the limits.
+type Queue struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	lastID int
+	jobs   []Job
+}
+
+func New() *Queue {
+	return &Queue{now: time.Now}
+}
+
+// SetClock replaces the clock used to timestamp jobs.
+func (q *Queue) SetClock(now func() time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.now = now
+}
+
+// Push adds a job to the end of the queue.
+func (q *Queue) Push(repo string, issue int, title, command string) Job {
+	return q.push(Job{Repo: repo, Issue: issue, Title: title, Command: command})
+}
+
+// PushStep adds the current step of an issue's pipeline to the end of the
+// queue.
+func (q *Queue) PushStep(repo string, issue int, title, command string) Job {
+	return q.push(Job{Repo: repo, Issue: issue, Title: title, Command: command, Step: true})
+}
+
+func (q *Queue) push(job Job) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastID++
+	job.ID, job.Queued = q.lastID, q.now()
+	q.jobs = append(q.jobs, job)
+	return job
+}
+
+// Jobs returns the waiting jobs in the order they will start, followed by
+// the started jobs whose agent has not been detected yet.
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		if j.Waiting() {
+			jobs = append(jobs, j)
+		}
+	}
+	for _, j := range q.jobs {
+		if !j.Waiting() {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// Waiting returns the number of jobs that have not started.
+func (q *Queue) Waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, j := range q.jobs {
+		if j.Waiting() {
+			n++
+		}
+	}
+	return n
+}
+
+// Cancel removes a waiting job.
+func (q *Queue) Cancel(id int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, err := q.waiting(id)
+	if err != nil {
+		return err
+	}
+	q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+	return nil
+}
+
+// Drop forgets a job whether or not it started, e.g. when its launch failed.
+func (q *Queue) Drop(id int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if i := q.index(id); i >= 0 {
+		q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+	}
+}
+
+// Move shifts a waiting job by delta places among the waiting jobs; negative
+// deltas move it towards the front.
+func (q *Queue) Move(id, delta int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, err := q.waiting(id)
+	if err != nil {
+		return err
+	}
+	for ; delta < 0; delta++ {
+		prev := i - 1
+		for prev >= 0 && !q.jobs[prev].Waiting() {
+			prev--
+		}
+		if prev < 0 {
+			break
+		}
+		q.jobs[i], q.jobs[prev] = q.jobs[prev], q.jobs[i]
+		i = prev
+	}
+	for ; delta > 0; delta-- {
+		next := i + 1
+		for next < len(q.jobs) && !q.jobs[next].Waiting() {
+			next++
+		}
+		if next >= len(q.jobs) {
+			break
+		}
+		q.jobs[i], q.jobs[next] = q.jobs[next], q.jobs[i]
+		i = next
+	}
+	return nil
+}
+
+// Promote starts a waiting job right away, regardless of the limits.
+func (q *Queue) Promote(id int) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, err := q.waiting(id)
+	if err != nil {
+		return Job{}, err
+	}
+	q.jobs[i].Started = q.now()
+	return q.jobs[i], nil
+}
+
+// Dispatch starts, in queue order, every waiting job that fits within limits
+// next to the running agents and the jobs still starting, and returns them.
+// A job blocked by its repo's limit does not hold up jobs of other repos.
+func (q *Queue) Dispatch(limits config.Limits, running []agent.Agent) []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+
+	total := len(running)
+	perRepo := make(map[string]int)
+	for _, a := range running {
+		perRepo[a.Repo]++
+	}
+	jobs := q.jobs[:0]
+	for _, j := range q.jobs {
+		if !j.Waiting() && (startedAgent(j, running) || now.Sub(j.Started) > StartTimeout) {
+			continue
+		}
+		if !j.Waiting() {
+			total++
+			perRepo[j.Repo]++
+		}
+		jobs = append(jobs, j)
+	}
+	q.jobs = jobs
+
+	var started []Job
+	for i, j := range q.jobs {
+		if !j.Waiting() {
+			continue
+		}
+		if limits.MaxAgents > 0 && total >= limits.MaxAgents {
+			break
+		}
+		if limit := limits.Repo(j.Repo); limit > 0 && perRepo[j.Repo] >= limit {
+			continue
+		}
+		q.jobs[i].Started = now
+		total++
+		perRepo[j.Repo]++
+		started = append(started, q.jobs[i])
+	}
+	return started
+}
+
+func startedAgent(j Job, running []agent.Agent) bool {
+	for _, a := range running {
+		if j.runs(a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) index(id int) int {
+	for i, j := range q.jobs {
+		if j.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *Queue) waiting(id int) (int, error) {
+	i := q.index(id)
+	if i < 0 {
+		return -1, ErrNoJob
+	}
+	if !q.jobs[i].Waiting() {
+		return -1, ErrStarted
+	}
+	return i, nil
+}
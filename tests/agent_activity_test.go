@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Tests for agent activity classification (busy / idle / waiting)
+// =============================================================================
+
+func Test_ActivityTracker_BusyProcessIsActive(t *testing.T) {
+	tracker := agent.NewActivityTracker()
+	agents := []agent.Agent{{Name: "OpenCode", PID: os.Getpid()}}
+
+	tracker.Classify(agents, scanSelf(t))
+	burnCPU(200 * time.Millisecond)
+	agents = tracker.Classify(agents, scanSelf(t))
+
+	assert.Equal(t, agent.StateBusy, agents[0].State)
+	assert.True(t, agents[0].IsActive)
+	assert.Greater(t, agents[0].CPUPercent, 3.0)
+	assert.Len(t, agent.FilterActive(agents, true), 1)
+}
+
+func Test_ActivityTracker_IdleProcessIsNotActive(t *testing.T) {
+	// A sleeping child stays idle regardless of what the test binary is doing
+	sleeper := exec.Command("sleep", "5")
+	if err := sleeper.Start(); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	defer func() {
+		sleeper.Process.Kill()
+		sleeper.Wait()
+	}()
+
+	tracker := agent.NewActivityTracker()
+	agents := []agent.Agent{{Name: "OpenCode", PID: sleeper.Process.Pid}}
+
+	tracker.Classify(agents, scanPID(t, sleeper.Process.Pid))
+	time.Sleep(300 * time.Millisecond)
+	agents = tracker.Classify(agents, scanPID(t, sleeper.Process.Pid))
+
+	assert.NotEqual(t, agent.StateBusy, agents[0].State)
+	assert.False(t, agents[0].IsActive)
+	assert.Empty(t, agent.FilterActive(agents, true))
+}
+
+func Test_ActivityTracker_VanishedProcessIsUnknown(t *testing.T) {
+	tracker := agent.NewActivityTracker()
+	agents := tracker.Classify([]agent.Agent{{Name: "OpenCode", PID: 1 << 30}}, scanSelf(t))
+
+	assert.Equal(t, agent.StateUnknown, agents[0].State)
+	assert.False(t, agents[0].IsActive)
+}
+
+func Test_AgentState_String(t *testing.T) {
+	assert.Equal(t, "busy", agent.StateBusy.String())
+	assert.Equal(t, "idle", agent.StateIdle.String())
+	assert.Equal(t, "waiting", agent.StateWaiting.String())
+	assert.Equal(t, "unknown", agent.StateUnknown.String())
+}
+
+func scanSelf(t *testing.T) []agent.Process {
+	return scanPID(t, os.Getpid())
+}
+
+func scanPID(t *testing.T, pid int) []agent.Process {
+	p, err := agent.NewProcScanner(agent.DefaultProcRoot).Process(pid)
+	if err != nil {
+		t.Skipf("procfs not available: %v", err)
+	}
+	return []agent.Process{p}
+}
+
+func burnCPU(d time.Duration) {
+	deadline := time.Now().Add(d)
+	n := 0
+	for time.Now().Before(deadline) {
+		n++
+	}
+	_ = n
+}
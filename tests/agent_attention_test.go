@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Tests for detecting agents that wait for user input
+// =============================================================================
+
+func Test_LooksLikePrompt_PermissionAndQuestions(t *testing.T) {
+	prompts := []string{
+		"Running tests...\nAllow bash command once? (y/n)\n",
+		"│ Do you want to make this edit to main.go?            │\n│ ❯ 1. Yes                                  │\n│   2. No │\n",
+		"Edit file main.go\n[Y/n] \n",
+		"Permission required: write to /etc/hosts\n",
+	}
+	for _, text := range prompts {
+		assert.True(t, agent.LooksLikePrompt(text), "expected prompt in %q", text)
+	}
+}
+
+func Test_LooksLikePrompt_IgnoresRegularOutput(t *testing.T) {
+	outputs := []string{
+		"",
+		"Implemented the feature.\nAll tests pass.\n",
+		"> /tdd 42\nWriting tests for issue #42\n",
+		"Implemented caching. Anything else?\n",
+	}
+	for _, text := range outputs {
+		assert.False(t, agent.LooksLikePrompt(text), "unexpected prompt in %q", text)
+	}
+}
+
+func Test_PromptTracker_OnlyRefinesIdleAgentsInPanes(t *testing.T) {
+	pane := agent.Pane{PID: 10, Session: "ai", ID: "%1"}
+	captured := map[string]string{"%1": "Do you want to proceed? (y/n)"}
+	capture := func(p agent.Pane) (string, error) {
+		text, ok := captured[p.ID]
+		if !ok {
+			return "", errors.New("no such pane")
+		}
+		return text, nil
+	}
+	agents := []agent.Agent{
+		{PID: 1, State: agent.StateIdle, Pane: pane},
+		{PID: 2, State: agent.StateBusy, Pane: pane},
+		{PID: 3, State: agent.StateWaiting, Pane: agent.Pane{PID: 11, ID: "%2"}},
+		{PID: 4, State: agent.StateIdle},
+	}
+
+	agents = agent.NewPromptTracker(capture).Refine(agents)
+
+	assert.Equal(t, agent.StateWaiting, agents[0].State)
+	assert.Equal(t, agent.StateBusy, agents[1].State)
+	assert.Equal(t, agent.StateWaiting, agents[2].State, "capture failure keeps CPU classification")
+	assert.Equal(t, agent.StateIdle, agents[3].State)
+	assert.Equal(t, 2, agent.CountNeedingAttention(agents))
+}
+
+func Test_PromptTracker_IdlePaneWithoutPromptIsIdle(t *testing.T) {
+	agents := []agent.Agent{{PID: 1, State: agent.StateWaiting, Pane: agent.Pane{PID: 10, ID: "%1"}}}
+
+	agents = agent.NewPromptTracker(func(agent.Pane) (string, error) {
+		return "Done. All tests pass.", nil
+	}).Refine(agents)
+
+	assert.Equal(t, agent.StateIdle, agents[0].State)
+	assert.False(t, agents[0].NeedsAttention())
+}
+
+func Test_PromptTracker_CapturesOncePerIdlePeriod(t *testing.T) {
+	pane := agent.Pane{PID: 10, ID: "%1"}
+	captures := 0
+	text := "Do you want to proceed? (y/n)"
+	tracker := agent.NewPromptTracker(func(agent.Pane) (string, error) {
+		captures++
+		return text, nil
+	})
+	scan := func(state agent.State) agent.State {
+		return tracker.Refine([]agent.Agent{{PID: 1, State: state, Pane: pane}})[0].State
+	}
+
+	assert.Equal(t, agent.StateWaiting, scan(agent.StateIdle))
+	text = "Done."
+	assert.Equal(t, agent.StateWaiting, scan(agent.StateIdle), "idle agent reuses its last capture")
+	assert.Equal(t, 1, captures)
+
+	assert.Equal(t, agent.StateBusy, scan(agent.StateBusy))
+	assert.Equal(t, agent.StateIdle, scan(agent.StateWaiting), "agent that stopped being busy is captured again")
+	assert.Equal(t, agent.StateIdle, scan(agent.StateIdle))
+	assert.Equal(t, 2, captures)
+}
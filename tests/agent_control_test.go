@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for stopping, interrupting and restarting agents
+// =============================================================================
+
+func Test_ShellJoin_QuotesPrompt(t *testing.T) {
+	argv := []string{"opencode-secure", "--model", "opencode/minimax-m2.5-free", "--prompt", "/tdd 42"}
+
+	assert.Equal(t, "opencode-secure --model opencode/minimax-m2.5-free --prompt '/tdd 42'", agent.ShellJoin(argv))
+	assert.Equal(t, `aider --message 'it'\''s fine' ''`, agent.ShellJoin([]string{"aider", "--message", "it's fine", ""}))
+}
+
+func Test_ExitStatusCommand_WritesTheAgentsExitStatus(t *testing.T) {
+	path := agent.ExitStatusPath(t.TempDir(), "simonbrundin/ai", 42, "/tdd")
+	assert.Equal(t, "simonbrundin-ai-42-tdd.status", filepath.Base(path))
+
+	line := agent.ExitStatusCommand([]string{"sh", "-c", "exit 3"}, path)
+	require.NoError(t, exec.Command("sh", "-c", line).Run())
+
+	status, err := agent.ReadExitStatus(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "reading consumes the status")
+}
+
+func Test_ExitStatusCommand_TerminatedAgentCountsAsStopped(t *testing.T) {
+	path := agent.ExitStatusPath(t.TempDir(), "simonbrundin/ai", 42, "/tdd")
+	line := agent.ExitStatusCommand([]string{"sh", "-c", "kill -TERM $$"}, path)
+	require.NoError(t, exec.Command("sh", "-c", line).Run())
+
+	status, err := agent.ReadExitStatus(path)
+	require.NoError(t, err)
+	assert.Equal(t, 143, status)
+	assert.True(t, agent.Stopped(status))
+	assert.True(t, agent.Stopped(130))
+	assert.False(t, agent.Stopped(1))
+}
+
+func Test_Terminate_SendsSIGTERM(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+
+	require.NoError(t, agent.Terminate(agent.Agent{PID: cmd.Process.Pid}))
+
+	err := cmd.Wait()
+	require.Error(t, err)
+	status := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	assert.Equal(t, syscall.SIGTERM, status.Signal())
+}
+
+func Test_Interrupt_OutsideTmuxSendsSIGINT(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+
+	require.NoError(t, agent.Interrupt(agent.Agent{PID: cmd.Process.Pid}))
+
+	_ = cmd.Wait()
+	status := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	assert.Equal(t, syscall.SIGINT, status.Signal())
+}
+
+func Test_Restart_RequiresTmuxPane(t *testing.T) {
+	err := agent.Restart(agent.Agent{PID: 1, Cmdline: []string{"opencode"}}, "")
+
+	assert.ErrorIs(t, err, agent.ErrNotInPane)
+}
+
+func Test_Terminate_ReportsMissingProcess(t *testing.T) {
+	assert.Error(t, agent.Terminate(agent.Agent{PID: 1 << 30}))
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the git status shown for each agent's working directory
+// =============================================================================
+
+func Test_ParseGitStatus_PorcelainV2(t *testing.T) {
+	output := "# branch.oid 1234567890abcdef\n" +
+		"# branch.head issue-42-cache\n" +
+		"# branch.upstream origin/issue-42-cache\n" +
+		"# branch.ab +2 -1\n" +
+		"1 .M N... 100644 100644 100644 abc def main.go\n" +
+		"1 A. N... 000000 100644 100644 000 abc agent/git file.go\n" +
+		"2 R. N... 100644 100644 100644 abc def R100 new.go\told.go\n" +
+		"? notes.txt\n"
+
+	status := agent.ParseGitStatus(output)
+
+	assert.Equal(t, "issue-42-cache", status.Branch)
+	assert.True(t, status.HasUpstream)
+	assert.Equal(t, 2, status.Ahead)
+	assert.Equal(t, 1, status.Behind)
+	assert.Equal(t, []agent.ChangedFile{
+		{XY: " M", Path: "main.go"},
+		{XY: "A ", Path: "agent/git file.go"},
+		{XY: "R ", Path: "new.go"},
+		{XY: "??", Path: "notes.txt"},
+	}, status.Files)
+	assert.Equal(t, 4, status.Dirty())
+}
+
+func Test_ReadGitStatus_RealRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# ai\n"), 0o644))
+	runGit(t, dir, "add", "README.md")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "Initial commit")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# ai tui\n"), 0o644))
+
+	status, err := agent.ReadGitStatus(dir)
+
+	require.NoError(t, err)
+	assert.Equal(t, "main", status.Branch)
+	assert.False(t, status.HasUpstream)
+	assert.Equal(t, "Initial commit", status.LastCommit)
+	assert.Equal(t, []agent.ChangedFile{{XY: " M", Path: "README.md"}}, status.Files)
+}
+
+func Test_ReadGitStatus_LeavesTheIndexAlone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	readme := filepath.Join(dir, "README.md")
+	require.NoError(t, os.WriteFile(readme, []byte("# ai\n"), 0o644))
+	runGit(t, dir, "add", "README.md")
+	// A new mtime makes a plain git status refresh and rewrite the index
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(readme, later, later))
+	index := filepath.Join(dir, ".git", "index")
+	before, err := os.Stat(index)
+	require.NoError(t, err)
+
+	_, err = agent.ReadGitStatus(dir)
+
+	require.NoError(t, err)
+	after, err := os.Stat(index)
+	require.NoError(t, err)
+	assert.Equal(t, before.ModTime(), after.ModTime())
+}
+
+func Test_AttachGitStatus_ReadsEachDirectoryOnce(t *testing.T) {
+	reads := map[string]int{}
+	read := func(dir string) (*agent.GitStatus, error) {
+		reads[dir]++
+		return &agent.GitStatus{Branch: "main"}, nil
+	}
+	agents := []agent.Agent{{PID: 1, WorkingDir: "/repos/ai"}, {PID: 2, WorkingDir: "/repos/ai"}, {PID: 3}}
+
+	agents = agent.AttachGitStatus(agents, read)
+
+	assert.Equal(t, 1, reads["/repos/ai"])
+	assert.Equal(t, "main", agents[1].Git.Branch)
+	assert.Nil(t, agents[2].Git)
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
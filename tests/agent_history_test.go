@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"ai-tui/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the agent session history behind the Timeline tab
+// =============================================================================
+
+// fakeClock returns a clock that starts at start and is advanced by hand
+func fakeClock(start time.Time) (func() time.Time, func(time.Duration)) {
+	now := start
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func Test_DefaultPath_UsesXDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	assert.Equal(t, "/tmp/state/ai-tui/history.jsonl", history.DefaultPath())
+}
+
+func Test_DefaultPath_FallsBackToLocalState(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	assert.Equal(t, "/home/user/.local/state/ai-tui/history.jsonl", history.DefaultPath())
+}
+
+func Test_Recorder_RecordsLifecycleEvents(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	rec := history.NewRecorder(filepath.Join(t.TempDir(), "history.jsonl"))
+	rec.SetClock(clock)
+
+	a := agent.Agent{Name: "OpenCode", PID: 1001, StartTime: start, Repo: "simonbrundin/ai", Issue: 42, Command: "/tdd", State: agent.StateBusy}
+
+	events, err := rec.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, history.EventStarted, events[0].Type)
+	assert.Equal(t, history.EventBusy, events[1].Type)
+
+	advance(10 * time.Minute)
+	events, err = rec.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	assert.Empty(t, events, "unchanged agents should not produce events")
+
+	a.State = agent.StateIdle
+	events, err = rec.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, history.EventIdle, events[0].Type)
+
+	advance(5 * time.Minute)
+	events, err = rec.Observe(nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, history.EventExited, events[0].Type)
+	assert.Equal(t, 15*time.Minute, events[0].Duration)
+	assert.Equal(t, 42, events[0].Issue)
+}
+
+func Test_Recorder_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	a := agent.Agent{Name: "OpenCode", PID: 1001, StartTime: start, State: agent.StateBusy}
+
+	first := history.NewRecorder(path)
+	first.SetClock(clock)
+	_, err := first.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+
+	advance(time.Minute)
+	second := history.NewRecorder(path)
+	second.SetClock(clock)
+	events, err := second.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	assert.Empty(t, events, "an agent still running after a restart is not started again")
+
+	loaded, err := history.Load(path)
+	require.NoError(t, err)
+	assert.Len(t, loaded, 2)
+}
+
+func Test_Recorder_PIDReuseIsANewSession(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	clock, _ := fakeClock(start)
+	rec := history.NewRecorder(filepath.Join(t.TempDir(), "history.jsonl"))
+	rec.SetClock(clock)
+
+	_, err := rec.Observe([]agent.Agent{{Name: "OpenCode", PID: 1001, StartTime: start}})
+	require.NoError(t, err)
+	events, err := rec.Observe([]agent.Agent{{Name: "aider", PID: 1001, StartTime: start.Add(time.Minute)}})
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, history.EventStarted, events[0].Type)
+	assert.Equal(t, "aider", events[0].Kind)
+	assert.Equal(t, history.EventExited, events[1].Type)
+	assert.Equal(t, "OpenCode", events[1].Kind)
+}
+
+func Test_Recorder_DropsOldEventsOnLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	a := agent.Agent{Name: "OpenCode", PID: 1001, StartTime: start, State: agent.StateBusy}
+
+	first := history.NewRecorder(path)
+	first.SetClock(clock)
+	_, err := first.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	advance(2 * time.Hour)
+	a.State = agent.StateIdle
+	_, err = first.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+
+	advance(30 * time.Minute)
+	second := history.NewRecorder(path)
+	second.SetClock(clock)
+	second.SetLimits(100, time.Hour)
+	events, err := second.Observe([]agent.Agent{a})
+	require.NoError(t, err)
+	assert.Empty(t, events, "an agent whose start was dropped is not started again")
+
+	loaded, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, history.EventIdle, loaded[0].Type)
+}
+
+func Test_Recorder_RewritesTheFileBeyondTwiceTheLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	rec := history.NewRecorder(path)
+	rec.SetClock(clock)
+	rec.SetLimits(4, 0)
+
+	states := []agent.State{agent.StateBusy, agent.StateIdle}
+	for i := 0; i < 7; i++ {
+		advance(time.Minute)
+		_, err := rec.Observe([]agent.Agent{{Name: "OpenCode", PID: 1001, StartTime: start, State: states[i%2]}})
+		require.NoError(t, err)
+	}
+	loaded, err := history.Load(path)
+	require.NoError(t, err)
+	assert.Len(t, loaded, 8, "the file grows up to twice the limit")
+
+	advance(time.Minute)
+	_, err = rec.Observe(nil)
+	require.NoError(t, err)
+
+	loaded, err = history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 4)
+	assert.Equal(t, history.EventExited, loaded[3].Type)
+	events, err := rec.Events()
+	require.NoError(t, err)
+	assert.Equal(t, loaded, events)
+}
+
+func Test_Sessions_SumsBusyTimeAndTotalsPerIssue(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	events := []history.Event{
+		{Time: at(9, 0), Type: history.EventStarted, Kind: "OpenCode", PID: 1, StartTime: at(9, 0), Repo: "simonbrundin/ai", Issue: 42, Command: "/tdd"},
+		{Time: at(9, 0), Type: history.EventBusy, PID: 1, StartTime: at(9, 0)},
+		{Time: at(9, 20), Type: history.EventIdle, PID: 1, StartTime: at(9, 0)},
+		{Time: at(9, 30), Type: history.EventExited, PID: 1, StartTime: at(9, 0)},
+		{Time: at(10, 0), Type: history.EventStarted, Kind: "OpenCode", PID: 2, StartTime: at(10, 0), Repo: "simonbrundin/ai", Issue: 42, Command: "/implement"},
+		{Time: at(10, 0), Type: history.EventBusy, PID: 2, StartTime: at(10, 0)},
+	}
+
+	sessions := history.Sessions(events, at(10, 15))
+
+	require.Len(t, sessions, 2)
+	assert.Equal(t, 2, sessions[0].PID, "newest session first")
+	assert.True(t, sessions[0].Running())
+	assert.Equal(t, 15*time.Minute, sessions[0].Busy)
+	assert.Equal(t, 30*time.Minute, sessions[1].Duration(at(10, 15)))
+	assert.Equal(t, 20*time.Minute, sessions[1].Busy)
+	assert.Equal(t, "/tdd", sessions[1].Command)
+
+	totals := history.TotalsByIssue(sessions, at(10, 15))
+	require.Len(t, totals, 1)
+	assert.Equal(t, history.IssueTotal{Repo: "simonbrundin/ai", Issue: 42, Sessions: 2, Duration: 45 * time.Minute, Busy: 35 * time.Minute}, totals[0])
+}
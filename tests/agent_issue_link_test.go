@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for linking running agents back to the GitHub issue they work on
+// =============================================================================
+
+func Test_ParseWindowName_CommandDialogWindows(t *testing.T) {
+	command, issue, ok := agent.ParseWindowName("opencode-/tdd-42")
+	assert.True(t, ok)
+	assert.Equal(t, "/tdd", command)
+	assert.Equal(t, 42, issue)
+
+	_, _, ok = agent.ParseWindowName("opencode-issue")
+	assert.False(t, ok)
+	_, _, ok = agent.ParseWindowName("main")
+	assert.False(t, ok)
+}
+
+func Test_ParsePrompt_CommandAndIssue(t *testing.T) {
+	command, issue, ok := agent.ParsePrompt("/implement 7")
+	assert.True(t, ok)
+	assert.Equal(t, "/implement", command)
+	assert.Equal(t, 7, issue)
+
+	command, issue, ok = agent.ParsePrompt("/docs #12")
+	assert.True(t, ok)
+	assert.Equal(t, "/docs", command)
+	assert.Equal(t, 12, issue)
+
+	_, _, ok = agent.ParsePrompt("/issue")
+	assert.False(t, ok)
+}
+
+func Test_RepoFromDir_ReadsOriginRemote(t *testing.T) {
+	dir := t.TempDir()
+	writeGitConfig(t, filepath.Join(dir, ".git"), "git@github.com:simonbrundin/ai.git")
+	sub := filepath.Join(dir, "agent")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+
+	assert.Equal(t, "simonbrundin/ai", agent.RepoFromDir(dir))
+	assert.Equal(t, "simonbrundin/ai", agent.RepoFromDir(sub))
+}
+
+func Test_RepoFromDir_HTTPSRemoteAndWorktree(t *testing.T) {
+	main := t.TempDir()
+	writeGitConfig(t, filepath.Join(main, ".git"), "https://github.com/simonbrundin/web")
+	worktreeGitDir := filepath.Join(main, ".git", "worktrees", "issue-42")
+	require.NoError(t, os.MkdirAll(worktreeGitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0o644))
+	worktree := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0o644))
+
+	assert.Equal(t, "simonbrundin/web", agent.RepoFromDir(worktree))
+	assert.Equal(t, "", agent.RepoFromDir(t.TempDir()))
+}
+
+func Test_LinkIssues_PrefersPromptOverWindowName(t *testing.T) {
+	agents := agent.LinkIssues([]agent.Agent{
+		{PID: 1, Metadata: agent.Metadata{"prompt": "/tdd 42"}, Pane: agent.Pane{Window: "opencode-/pr-9"}},
+		{PID: 2, Pane: agent.Pane{Window: "opencode-/implement-7"}},
+		{PID: 3},
+	})
+
+	assert.Equal(t, "/tdd", agents[0].Command)
+	assert.Equal(t, 42, agents[0].Issue)
+	assert.Equal(t, "/implement", agents[1].Command)
+	assert.Equal(t, 7, agents[1].Issue)
+	assert.Zero(t, agents[2].Issue)
+}
+
+func writeGitConfig(t *testing.T, gitDir, url string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	config := "[core]\n\tbare = false\n[remote \"upstream\"]\n\turl = git@github.com:other/fork.git\n" +
+		"[remote \"origin\"]\n\turl = " + url + "\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n"
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "config"), []byte(config), 0o644))
+}
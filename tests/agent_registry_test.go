@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Tests for the pluggable agent kind registry
+// =============================================================================
+
+func Test_AgentRegistry_MatchesBuiltinKinds(t *testing.T) {
+	cases := []struct {
+		argv []string
+		kind string
+	}{
+		{[]string{"/usr/local/bin/opencode", "--model", "x"}, "OpenCode"},
+		{[]string{"/home/simon/repos/dotfiles/opencode/.config/opencode/opencode-secure"}, "OpenCode"},
+		{[]string{"node", "/usr/local/bin/claude"}, "Claude Code"},
+		{[]string{"claude", "--model", "opus"}, "Claude Code"},
+		{[]string{"python3", "-m", "aider", "--model", "gpt-4o"}, "aider"},
+		{[]string{"/usr/bin/aider"}, "aider"},
+		{[]string{"node", "/usr/local/bin/codex"}, "Codex CLI"},
+		{[]string{"gemini"}, "Gemini CLI"},
+		{[]string{"goose", "session"}, "goose"},
+		{[]string{"vim", "opencode.json"}, ""},
+	}
+
+	for _, c := range cases {
+		kind, ok := agent.MatchKind(c.argv)
+		if c.kind == "" {
+			assert.False(t, ok, "argv %v should not match any kind", c.argv)
+			continue
+		}
+		assert.True(t, ok, "argv %v should match %s", c.argv, c.kind)
+		assert.Equal(t, c.kind, kind.Name)
+	}
+}
+
+func Test_AgentRegistry_ExtractsOpenCodeMetadata(t *testing.T) {
+	argv := []string{"opencode-secure", "--model", "opencode/minimax-m2.5-free", "--prompt", "/tdd 42"}
+
+	kind, ok := agent.MatchKind(argv)
+	assert.True(t, ok)
+	md := kind.Extract(argv)
+
+	assert.Equal(t, "opencode/minimax-m2.5-free", md["model"])
+	assert.Equal(t, "/tdd 42", md["prompt"])
+}
+
+func Test_AgentRegistry_ExtractFlags_SupportsEqualsSyntax(t *testing.T) {
+	md := agent.ExtractFlags("model")([]string{"aider", "--model=sonnet"})
+
+	assert.Equal(t, "sonnet", md["model"])
+}
+
+func Test_AgentRegistry_RegisterCustomKind(t *testing.T) {
+	agent.Register(agent.Kind{Name: "test-agent", Match: agent.MatchCommand("test-agent")})
+	t.Cleanup(func() { agent.Unregister("test-agent") })
+
+	kind, ok := agent.MatchKind([]string{"/opt/bin/test-agent", "--flag"})
+
+	assert.True(t, ok)
+	assert.Equal(t, "test-agent", kind.Name)
+}
+
+func Test_AgentRegistry_Unregister(t *testing.T) {
+	agent.Register(agent.Kind{Name: "test-agent", Match: agent.MatchCommand("test-agent")})
+	agent.Unregister("test-agent")
+
+	_, ok := agent.MatchKind([]string{"test-agent"})
+
+	assert.False(t, ok)
+}
+
+func Test_ParseCmdline_SplitsOnNUL(t *testing.T) {
+	argv := agent.ParseCmdline([]byte("opencode\x00--prompt\x00/tdd 42\x00"))
+
+	assert.Equal(t, []string{"opencode", "--prompt", "/tdd 42"}, argv)
+	assert.Nil(t, agent.ParseCmdline(nil))
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"ai-tui/github"
+	"ai-tui/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Tests for sorting the Agents tab by its resource columns
+// =============================================================================
+
+func Test_AgentsTab_SortsByColumnInBothDirections(t *testing.T) {
+	start := time.Now().Add(-3 * time.Hour)
+	agents := []agent.Agent{
+		// 101 and 103 tie on CPU and children, and are ordered by PID
+		{Name: "OpenCode", PID: 101, WorkingDir: "/home/user/ai", StartTime: start,
+			CPUPercent: 5, RSS: 300 << 20, SubProcesses: []agent.Process{{PID: 1011}}},
+		{Name: "OpenCode", PID: 102, WorkingDir: "/home/user/ai", StartTime: start.Add(2 * time.Hour),
+			CPUPercent: 20, RSS: 100 << 20},
+		{Name: "OpenCode", PID: 103, WorkingDir: "/home/user/ai", StartTime: start.Add(time.Hour),
+			CPUPercent: 5, RSS: 200 << 20, SubProcesses: []agent.Process{{PID: 1031}}},
+	}
+
+	tests := []struct {
+		name   string
+		column int // presses of "s" from the PID column
+		asc    []int
+	}{
+		{"pid", 0, []int{101, 102, 103}},
+		{"uptime", 1, []int{102, 103, 101}},
+		{"cpu", 2, []int{101, 103, 102}},
+		{"memory", 3, []int{102, 103, 101}},
+		{"children", 4, []int{102, 101, 103}},
+	}
+	for _, tt := range tests {
+		for _, desc := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s desc=%v", tt.name, desc), func(t *testing.T) {
+				m := newSortModel(t, agents)
+				for i := 0; i < tt.column; i++ {
+					m = pressKey(m, "s")
+				}
+				want := tt.asc
+				if desc {
+					m = pressKey(m, "S")
+					want = reversed(tt.asc)
+				}
+				assert.Equal(t, want, agentRowOrder(m.View(), agents))
+			})
+		}
+	}
+}
+
+// newSortModel returns the TUI on the Agents tab after a refresh that
+// detected agents
+func newSortModel(t *testing.T, agents []agent.Agent) tea.Model {
+	var m tea.Model = tui.New(tui.Options{
+		Detector:     agent.NewScriptedDetector(agent.ScriptStep{Agents: agents}),
+		Tracker:      github.NewFake(),
+		ScanInterval: -1,
+		StateDir:     t.TempDir(),
+	})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = pressKey(m, "r")
+	m = pressKey(m, "2")
+	return pressKey(m, "v")
+}
+
+// pressKey sends a key and feeds the messages of its commands back to m
+func pressKey(m tea.Model, k string) tea.Model {
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	return runCmd(m, cmd)
+}
+
+func runCmd(m tea.Model, cmd tea.Cmd) tea.Model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = runCmd(m, c)
+		}
+		return m
+	}
+	m, cmd = m.Update(msg)
+	return runCmd(m, cmd)
+}
+
+// agentRowOrder returns the PIDs of agents in the order their rows appear
+func agentRowOrder(view string, agents []agent.Agent) []int {
+	var pids []int
+	for _, a := range agents {
+		if strings.Contains(view, fmt.Sprintf("%-8d", a.PID)) {
+			pids = append(pids, a.PID)
+		}
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		return strings.Index(view, fmt.Sprintf("%-8d", pids[i])) < strings.Index(view, fmt.Sprintf("%-8d", pids[j]))
+	})
+	return pids
+}
+
+func reversed(pids []int) []int {
+	out := make([]int, len(pids))
+	for i, pid := range pids {
+		out[len(pids)-1-i] = pid
+	}
+	return out
+}
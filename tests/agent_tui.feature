@@ -0,0 +1,107 @@
+Feature: Agents tab driven by a scripted agent detector
+  As a developer
+  I want the real TUI to run against a fake agent source
+  So that agent scenarios exercise the actual rendering and key handling
+
+  Background:
+    Given the monitor runs with a scripted agent detector
+
+  Scenario: Detected agents are grouped by kind
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir    | state |
+      | OpenCode | 1001 | /home/user/ai  | busy  |
+      | OpenCode | 1002 | /home/user/ai  | idle  |
+      | aider    | 2001 | /home/user/web | idle  |
+    When the monitor refreshes
+    And I switch to the agents tab
+    Then the screen shows "OpenCode (2)"
+    And the screen shows "aider (1)"
+    And the screen shows "1002"
+
+  Scenario: Agents that exit disappear on the next refresh
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir   | state |
+      | OpenCode | 1001 | /home/user/ai | busy  |
+      | OpenCode | 1002 | /home/user/ai | idle  |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state |
+      | OpenCode | 1001 | /home/user/ai | busy  |
+    When the monitor refreshes
+    And I switch to the agents tab
+    Then the screen shows "OpenCode (2)"
+    When the monitor refreshes
+    Then the screen shows "OpenCode (1)"
+    And the screen does not show "1002"
+
+  Scenario: The active filter hides agents that are not busy
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir    | state   |
+      | OpenCode | 1001 | /home/user/ai  | busy    |
+      | OpenCode | 1002 | /home/user/web | waiting |
+    When the monitor refreshes
+    And I switch to the agents tab
+    And I press the "a" key
+    Then the screen shows "[Filtering: active only]"
+    And the screen shows "1001"
+    And the screen does not show "1002"
+
+  Scenario: Agent list keys are typed into the help search
+    When the monitor refreshes
+    And I switch to the agents tab
+    And I press the "?" key
+    And I press the "s" key
+    And I press the "S" key
+    Then the screen shows "Search: sS"
+
+  Scenario: The footer lists the agent actions
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir   | state |
+      | OpenCode | 1001 | /home/user/ai | busy  |
+    When the monitor refreshes
+    And I switch to the agents tab
+    Then the screen shows "e: files  w: waiting  i: interrupt  x: stop  R: restart"
+    And the screen shows "?: help"
+    When I press the "?" key
+    And I press the "e" key
+    And I press the "x" key
+    Then the screen shows "Search: ex"
+
+  Scenario: Agent detection errors are shown to the user
+    Given the detector fails with "permission denied"
+    When the monitor refreshes
+    And I switch to the agents tab
+    Then the screen shows "agent detection failed: permission denied"
+
+  Scenario: Finished agent sessions appear on the timeline
+    Given the monitor records agent history
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I switch to the timeline tab
+    Then the screen shows "#42"
+    And the screen shows "(running)"
+    When the monitor refreshes
+    Then the screen shows "📁 ai"
+    And the screen shows "1 session"
+    And the screen does not show "(running)"
+
+  Scenario: The background watcher updates the Agents tab without a refresh
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir   | state |
+      | OpenCode | 1001 | /home/user/ai | busy  |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state |
+      | OpenCode | 1001 | /home/user/ai | busy  |
+      | aider    | 2001 | /home/user/ai | idle  |
+    And on the next refresh the detector reports no agents
+    When I switch to the agents tab
+    And the background watcher scans
+    Then the screen shows "OpenCode (1)"
+    And the screen shows "OpenCode (PID 1001) started"
+    When the background watcher scans
+    Then the screen shows "aider (PID 2001) started"
+    When the background watcher scans
+    Then the screen shows "No agents running"
+    And the screen shows "exited"
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the background agent watcher
+// =============================================================================
+
+func Test_DiffAgents_ReportsStartedAndExited(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	prev := []agent.Agent{{PID: 1, StartTime: start}, {PID: 2, StartTime: start}}
+	next := []agent.Agent{{PID: 2, StartTime: start}, {PID: 3, StartTime: start}}
+
+	started, exited := agent.DiffAgents(prev, next)
+
+	require.Len(t, started, 1)
+	assert.Equal(t, 3, started[0].PID)
+	require.Len(t, exited, 1)
+	assert.Equal(t, 1, exited[0].PID)
+}
+
+func Test_DiffAgents_ReusedPIDIsANewAgent(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	prev := []agent.Agent{{PID: 1, StartTime: start}}
+	next := []agent.Agent{{PID: 1, StartTime: start.Add(time.Minute)}}
+
+	started, exited := agent.DiffAgents(prev, next)
+
+	assert.Len(t, started, 1)
+	assert.Len(t, exited, 1)
+}
+
+func Test_Watcher_DiffsSuccessiveScans(t *testing.T) {
+	opencode := agent.Agent{Name: "OpenCode", PID: 1001}
+	aider := agent.Agent{Name: "aider", PID: 2001}
+	detector := agent.NewScriptedDetector().
+		Then([]agent.Agent{opencode}, nil).
+		Then([]agent.Agent{opencode, aider}, nil).
+		Then(nil, agent.ErrNoAgentsFound)
+	w := agent.NewWatcher(detector, 0)
+
+	snap := w.Scan()
+	require.NoError(t, snap.Err)
+	assert.Equal(t, []agent.Agent{opencode}, snap.Started)
+	assert.Empty(t, snap.Exited)
+
+	snap = w.Scan()
+	assert.Equal(t, []agent.Agent{aider}, snap.Started)
+	assert.Empty(t, snap.Exited)
+
+	snap = w.Scan()
+	require.NoError(t, snap.Err, "no agents is an empty snapshot, not an error")
+	assert.Empty(t, snap.Agents)
+	assert.ElementsMatch(t, []agent.Agent{opencode, aider}, snap.Exited)
+}
+
+func Test_Watcher_FailedScanKeepsBaseline(t *testing.T) {
+	opencode := agent.Agent{Name: "OpenCode", PID: 1001}
+	detector := agent.NewScriptedDetector().
+		Then([]agent.Agent{opencode}, nil).
+		Then(nil, errors.New("permission denied")).
+		Then([]agent.Agent{opencode}, nil)
+	w := agent.NewWatcher(detector, 0)
+
+	w.Scan()
+	snap := w.Scan()
+	assert.ErrorContains(t, snap.Err, "agent detection failed: permission denied")
+
+	snap = w.Scan()
+	require.NoError(t, snap.Err)
+	assert.Empty(t, snap.Started, "a failed scan must not make running agents look new")
+	assert.Empty(t, snap.Exited)
+}
+
+func Test_Watcher_DefaultsInterval(t *testing.T) {
+	w := agent.NewWatcher(agent.NewScriptedDetector(), 0)
+	assert.Equal(t, agent.DefaultWatchInterval, w.Interval)
+}
+
+func Test_Watcher_NextWaitsForInterval(t *testing.T) {
+	detector := agent.NewScriptedDetector().Then([]agent.Agent{{PID: 1}}, nil)
+	w := agent.NewWatcher(detector, 20*time.Millisecond)
+
+	begin := time.Now()
+	snap := w.Next()
+
+	assert.GreaterOrEqual(t, time.Since(begin), 20*time.Millisecond)
+	assert.Len(t, snap.Started, 1)
+	assert.Equal(t, 1, detector.Calls())
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the git worktree each issue's agents can run in
+// =============================================================================
+
+func Test_IssueBranch_SlugsTheTitle(t *testing.T) {
+	assert.Equal(t, "issue-42-cache-agents", agent.IssueBranch(42, "Cache agents"))
+	assert.Equal(t, "issue-7-lägg-till-en-tidslinje", agent.IssueBranch(7, "Lägg till en tidslinje!"))
+	assert.Equal(t, "issue-9-fix-ci", agent.IssueBranch(9, "  [Fix] CI: "))
+	assert.Equal(t, "issue-3", agent.IssueBranch(3, "???"))
+	assert.LessOrEqual(t, len(agent.IssueBranch(1, "a very long title that goes on and on well past any sensible branch name")), 60)
+}
+
+func Test_ParseWorktreeList_Porcelain(t *testing.T) {
+	output := "worktree /home/user/repos/ai\nHEAD abc\nbranch refs/heads/main\n\n" +
+		"worktree /home/user/repos/ai.worktrees/issue-42-cache-agents\nHEAD def\nbranch refs/heads/issue-42-cache-agents\n\n" +
+		"worktree /tmp/detached\nHEAD 123\ndetached\n"
+
+	assert.Equal(t, []agent.Worktree{
+		{Dir: "/home/user/repos/ai", Branch: "main"},
+		{Dir: "/home/user/repos/ai.worktrees/issue-42-cache-agents", Branch: "issue-42-cache-agents"},
+		{Dir: "/tmp/detached"},
+	}, agent.ParseWorktreeList(output))
+}
+
+func Test_EnsureIssueWorktree_CreatesReusesAndRemoves(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	checkout := filepath.Join(t.TempDir(), "ai")
+	require.NoError(t, os.Mkdir(checkout, 0o755))
+	runGit(t, checkout, "init", "-q", "-b", "main")
+	runGit(t, checkout, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	wt, err := agent.EnsureIssueWorktree(checkout, 42, "Cache agents")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(checkout+".worktrees", "issue-42-cache-agents"), wt.Dir)
+	assert.DirExists(t, wt.Dir)
+
+	again, err := agent.EnsureIssueWorktree(checkout, 42, "Cache agents per scan")
+	require.NoError(t, err)
+	assert.Equal(t, "issue-42-cache-agents", again.Branch, "a renamed issue keeps its worktree")
+
+	_, found, err := agent.FindIssueWorktree(checkout, 4)
+	require.NoError(t, err)
+	assert.False(t, found, "issue 4 does not match issue-42")
+
+	require.NoError(t, agent.RemoveWorktree(checkout, wt.Dir))
+	assert.NoDirExists(t, wt.Dir)
+
+	wt, err = agent.EnsureIssueWorktree(checkout, 42, "Cache agents")
+	require.NoError(t, err, "the kept branch is checked out again")
+	assert.Equal(t, "issue-42-cache-agents", wt.Branch)
+}
+
+func Test_EnsureIssueWorktree_ConcurrentLaunchesShareTheWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	checkout := filepath.Join(t.TempDir(), "ai")
+	require.NoError(t, os.Mkdir(checkout, 0o755))
+	runGit(t, checkout, "init", "-q", "-b", "main")
+	runGit(t, checkout, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	results := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := agent.EnsureIssueWorktree(checkout, 42, "Cache agents")
+			results <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, <-results)
+	}
+}
@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-tui/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the configuration file
+// =============================================================================
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func Test_ConfigDefaultPath_UsesXDGConfigHome(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	assert.Equal(t, "/tmp/config/ai-tui/config.yaml", config.DefaultPath())
+}
+
+func Test_ConfigLoad_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	require.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+}
+
+func Test_ConfigLoad_GitHubSources(t *testing.T) {
+	path := writeConfig(t, `
+github:
+  owners: [simonbrundin]
+  orgs: [acme, acme-labs]
+  repos: [friend/tools]
+  exclude: ["acme/legacy-*", "acme-labs/sandbox"]
+`)
+
+	cfg, err := config.Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "acme-labs"}, cfg.GitHub.Orgs)
+	assert.Equal(t,
+		"is:open archived:false user:simonbrundin org:acme org:acme-labs repo:friend/tools",
+		cfg.GitHub.SearchQuery())
+}
+
+func Test_ConfigLoad_OrgsReplaceDefaultOwner(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "github:\n  orgs: [acme]\n"))
+
+	require.NoError(t, err)
+	assert.Empty(t, cfg.GitHub.Owners)
+	assert.Equal(t, "is:open archived:false org:acme", cfg.GitHub.SearchQuery())
+}
+
+func Test_ConfigLoad_ExcludeOnlyKeepsDefaultOwner(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "github:\n  exclude: [\"simonbrundin/dotfiles\"]\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, config.Default().GitHub.Owners, cfg.GitHub.Owners)
+	assert.True(t, cfg.GitHub.Excluded("simonbrundin/dotfiles"))
+}
+
+func Test_ConfigLoad_RejectsInvalidSettings(t *testing.T) {
+	_, err := config.Load(writeConfig(t, "github:\n  exclude: [\"acme/[\"]\n"))
+	assert.ErrorContains(t, err, "bad pattern")
+
+	_, err = config.Load(writeConfig(t, "github:\n  repos: [tools]\n"))
+	assert.ErrorContains(t, err, "not owner/name")
+
+	_, err = config.Load(writeConfig(t, "github: [\n"))
+	assert.ErrorContains(t, err, "parse")
+}
+
+func Test_GitHubSources_Excluded(t *testing.T) {
+	sources := config.GitHub{Exclude: []string{"acme/legacy-*", "*/sandbox"}}
+
+	assert.True(t, sources.Excluded("acme/legacy-api"))
+	assert.True(t, sources.Excluded("ACME/Legacy-Web"), "matching ignores case")
+	assert.True(t, sources.Excluded("simonbrundin/sandbox"))
+	assert.False(t, sources.Excluded("acme/api"))
+}
+
+func Test_ConfigLoad_AgentAndLocalSections(t *testing.T) {
+	path := writeConfig(t, `
+agent:
+  binary: /opt/bin/opencode-secure
+  model: opencode/minimax-m2.5-free
+local:
+  roots: [/src, /work]
+  paths:
+    acme/api: /work/acme-api
+  worktrees: true
+`)
+
+	cfg, err := config.Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, config.Agent{Binary: "/opt/bin/opencode-secure", Model: "opencode/minimax-m2.5-free"}, cfg.Agent)
+	assert.Equal(t, []string{"/src", "/work"}, cfg.Local.Roots)
+	assert.True(t, cfg.Local.Worktrees)
+	assert.Equal(t, config.Default().GitHub, cfg.GitHub, "sections left out keep their defaults")
+
+	_, err = config.Load(writeConfig(t, "local:\n  paths:\n    api: /work/api\n"))
+	assert.ErrorContains(t, err, "not owner/name")
+}
+
+func Test_AgentCommand_OmitsModelWhenUnset(t *testing.T) {
+	assert.Equal(t, []string{"opencode", "--prompt", "/issue"},
+		config.Agent{Binary: "opencode"}.Command("/issue"))
+	assert.Equal(t, []string{"opencode", "--model", "m", "--prompt", "/plan 42"},
+		config.Agent{Binary: "opencode", Model: "m"}.Command("/plan 42"))
+}
+
+func Test_LocalRepoPath_PrefersOverridesThenExistingCheckouts(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(second, "api"), 0o755))
+	local := config.Local{
+		Roots: []string{first, second},
+		Paths: map[string]string{"acme/web": "/elsewhere/web"},
+	}
+
+	assert.Equal(t, "/elsewhere/web", local.RepoPath("acme/web"))
+	assert.Equal(t, filepath.Join(second, "api"), local.RepoPath("acme/api"))
+	assert.Equal(t, filepath.Join(first, "tools"), local.RepoPath("acme/tools"), "falls back to the first root")
+	assert.Empty(t, local.RepoPath("tools"))
+}
+
+func Test_ExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+
+	assert.Equal(t, "/home/dev/repos", config.ExpandHome("~/repos"))
+	assert.Equal(t, "/srv/repos", config.ExpandHome("/srv/repos"))
+	assert.Equal(t, "~other/repos", config.ExpandHome("~other/repos"))
+}
+
+func Test_ConfigLoad_Commands(t *testing.T) {
+	path := writeConfig(t, `
+agents:
+  claude:
+    binary: claude
+    prompt_flag: ""
+commands:
+  - label: Planera
+    prompt: /plan {number}
+  - label: Granska
+    prompt: "Review {repo}#{number}"
+    agent: claude
+    model: opus
+`)
+
+	cfg, err := config.Load(path)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Commands, 2)
+	assert.Equal(t, []string{"opencode", "--prompt", "/plan 7"},
+		cfg.AgentFor(cfg.Commands[0]).Command(cfg.Commands[0].Render(config.IssueVars{Number: 7})))
+	assert.Equal(t, []string{"claude", "--model", "opus", "Review acme/api#7"},
+		cfg.AgentFor(cfg.Commands[1]).Command(cfg.Commands[1].Render(config.IssueVars{Number: 7, Repo: "acme/api"})))
+}
+
+func Test_ConfigLoad_NoCommandsUsesDefaults(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "agent:\n  model: m\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultCommands(), cfg.Commands)
+}
+
+func Test_ConfigLoad_RejectsInvalidCommands(t *testing.T) {
+	_, err := config.Load(writeConfig(t, "commands:\n  - label: Planera\n"))
+	assert.ErrorContains(t, err, "label and prompt are required")
+
+	_, err = config.Load(writeConfig(t, "commands:\n  - label: Granska\n    prompt: /review\n    agent: claude\n"))
+	assert.ErrorContains(t, err, `unknown agent "claude"`)
+
+	_, err = config.Load(writeConfig(t, "agents:\n  claude:\n    binary: claude\ncommands:\n"+
+		"  - label: Granska\n    prompt: /review {number}\n"+
+		"  - label: Granska med Claude\n    prompt: /review {number}\n    agent: claude\n"))
+	assert.ErrorContains(t, err, "commands[1]: /review is already the name of commands[0]")
+}
+
+func Test_Command_RenderAndName(t *testing.T) {
+	vars := config.IssueVars{Number: 42, Title: "Cache agents", Repo: "simonbrundin/ai", Labels: []string{"bug", "tester"}}
+
+	assert.Equal(t, "Fix simonbrundin/ai#42 (Cache agents) [bug,tester]",
+		config.Command{Prompt: "Fix {repo}#{number} ({title}) [{labels}]"}.Render(vars))
+	assert.Equal(t, "/tdd", config.Command{Label: "Skriv tester", Prompt: "/tdd {number}"}.Name())
+	assert.Equal(t, "/skriv-tester", config.Command{Label: "Skriv tester", Prompt: "Write tests for {number}"}.Name())
+}
+
+func Test_Agent_WindowPrefix(t *testing.T) {
+	assert.Equal(t, "opencode", config.Agent{Binary: "~/bin/opencode-secure"}.WindowPrefix())
+	assert.Equal(t, "claude", config.Agent{Binary: "claude"}.WindowPrefix())
+}
+
+func Test_ConfigLoad_Workflows(t *testing.T) {
+	path := writeConfig(t, `
+phases:
+  - {label: tester, next: [implementation]}
+  - {label: implementation, color: 0e8a16, next: [tester]}
+workflows:
+  acme/api:
+    - {label: spike, next: [design]}
+    - {label: design}
+`)
+
+	cfg, err := config.Load(path)
+
+	require.NoError(t, err)
+	global := cfg.Workflow("simonbrundin/ai")
+	assert.Equal(t, config.DefaultPhaseColor, global[0].Color)
+	assert.Equal(t, "0e8a16", global[1].Color)
+	assert.Equal(t, []config.Phase{global[1]}, global.Transitions([]string{"bug", "tester"}))
+
+	api := cfg.Workflow("acme/api")
+	assert.Len(t, api.Transitions(nil), 2, "an issue without a phase may enter any phase")
+	assert.Empty(t, api.Transitions([]string{"design"}), "design is final")
+	assert.False(t, api.IsPhase("tester"))
+}
+
+func Test_ConfigLoad_NoPhasesUsesDefaultWorkflow(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "agent:\n  model: m\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, config.Default().Phases, cfg.Workflow("acme/api"))
+	assert.Len(t, cfg.Phases, 6)
+	assert.Len(t, cfg.Phases.Transitions([]string{"tester"}), 6, "the default workflow does not restrict transitions")
+	assert.Equal(t, "Issue is in test phase", cfg.Phases[0].Description)
+	next, ok := cfg.Phases.After("docs")
+	assert.True(t, ok)
+	assert.Equal(t, "user_test", next.Label)
+	_, ok = cfg.Phases.After("pr")
+	assert.False(t, ok)
+}
+
+func Test_ConfigLoad_RejectsInvalidWorkflows(t *testing.T) {
+	_, err := config.Load(writeConfig(t, "phases:\n  - {label: build, next: [ship]}\n"))
+	assert.ErrorContains(t, err, `unknown next phase "ship"`)
+
+	_, err = config.Load(writeConfig(t, "phases:\n  - {label: build, color: green}\n"))
+	assert.ErrorContains(t, err, "not six hex digits")
+
+	_, err = config.Load(writeConfig(t, "phases:\n  - {label: build}\n  - {label: Build}\n"))
+	assert.ErrorContains(t, err, "duplicate phase")
+
+	_, err = config.Load(writeConfig(t, "workflows:\n  api:\n    - {label: build}\n"))
+	assert.ErrorContains(t, err, "not owner/name")
+}
+
+func Test_ConfigLoad_CommandPhases(t *testing.T) {
+	_, err := config.Load(writeConfig(t, "commands:\n  - {label: Bygg, prompt: /build, phase: build}\n"))
+	assert.ErrorContains(t, err, `unknown phase "build"`)
+
+	_, err = config.Load(writeConfig(t, "commands:\n  - {label: Bygg, prompt: /build, advance: true}\n"))
+	assert.ErrorContains(t, err, "advance needs a phase")
+
+	cfg, err := config.Load(writeConfig(t, "phases:\n  - {label: tester, next: [done]}\n  - {label: done}\n"))
+	require.NoError(t, err)
+	tdd, _ := cfg.CommandNamed("/tdd")
+	implement, _ := cfg.CommandNamed("/implement")
+	assert.Equal(t, "tester", tdd.Phase)
+	assert.Empty(t, implement.Phase, "default commands drop phases the workflow lacks")
+
+	next, ok := cfg.Phases.After("tester")
+	assert.True(t, ok)
+	assert.Equal(t, "done", next.Label)
+	_, ok = cfg.Phases.After("done")
+	assert.False(t, ok)
+}
+
+func Test_ConfigLoad_Pipelines(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "pipelines:\n  - {name: Snabb, steps: [/tdd, /pr]}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []config.Pipeline{{Name: "Snabb", Steps: []string{"/tdd", "/pr"}}}, cfg.Pipelines)
+
+	cfg, err = config.Load(writeConfig(t, "agent:\n  model: m\n"))
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultPipelines(), cfg.Pipelines)
+
+	_, err = config.Load(writeConfig(t, "pipelines:\n  - {name: Snabb, steps: [/tdd, /deploy]}\n"))
+	assert.ErrorContains(t, err, `unknown command "/deploy"`)
+
+	_, err = config.Load(writeConfig(t, "pipelines:\n  - {name: Tom}\n"))
+	assert.ErrorContains(t, err, "name and steps are required")
+}
+
+func Test_ConfigLoad_Limits(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t, "limits:\n  max_agents: 4\n  max_per_repo: 2\n  repos:\n    acme/api: 1\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, cfg.Limits.MaxAgents)
+	assert.Equal(t, 1, cfg.Limits.Repo("acme/api"))
+	assert.Equal(t, 2, cfg.Limits.Repo("acme/web"))
+
+	_, err = config.Load(writeConfig(t, "limits:\n  max_agents: -1\n"))
+	assert.ErrorContains(t, err, "must not be negative")
+
+	_, err = config.Load(writeConfig(t, "limits:\n  repos:\n    api: 1\n"))
+	assert.ErrorContains(t, err, "not owner/name")
+}
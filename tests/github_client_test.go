@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-tui/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the GitHub REST client, run against a local httptest server
+// =============================================================================
+
+// newGitHubTestClient returns a client talking to a server using handler
+func newGitHubTestClient(t *testing.T, handler http.HandlerFunc) *github.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := github.NewClient("test-token")
+	c.BaseURL = srv.URL
+	return c
+}
+
+func Test_GitHubClient_SearchIssues(t *testing.T) {
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/issues", r.URL.Path)
+		assert.Equal(t, "owner:simonbrundin is:open is:issue", r.URL.Query().Get("q"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		io.WriteString(w, `{"total_count": 2, "items": [
+			{"number": 42, "title": "Cache agents", "state": "open",
+			 "labels": [{"name": "tester"}], "repository_url": "https://api.github.com/repos/simonbrundin/ai"},
+			{"number": 7, "title": "Docs", "state": "open",
+			 "labels": [], "repository_url": "https://api.github.com/repos/simonbrundin/web"}
+		]}`)
+	})
+
+	page, err := c.SearchIssues("owner:simonbrundin is:open", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Zero(t, page.NextPage)
+	assert.Equal(t, []github.Issue{
+		{Number: 42, Title: "Cache agents", State: "open", Labels: []string{"tester"}, Repo: "simonbrundin/ai"},
+		{Number: 7, Title: "Docs", State: "open", Labels: []string{}, Repo: "simonbrundin/web"},
+	}, page.Issues)
+}
+
+// searchPageJSON renders a search response with n items numbered from first
+func searchPageJSON(total, first, n int) string {
+	var items []string
+	for i := 0; i < n; i++ {
+		items = append(items, fmt.Sprintf(`{"number": %d, "title": "Issue %d", "state": "open",
+			"repository_url": "https://api.github.com/repos/simonbrundin/ai"}`, first+i, first+i))
+	}
+	return fmt.Sprintf(`{"total_count": %d, "items": [%s]}`, total, strings.Join(items, ","))
+}
+
+func Test_GitHubClient_SearchIssuesPaginates(t *testing.T) {
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		switch r.URL.Query().Get("page") {
+		case "1":
+			io.WriteString(w, searchPageJSON(150, 1, 100))
+		case "2":
+			io.WriteString(w, searchPageJSON(150, 101, 50))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	first, err := c.SearchIssues("is:open", 1)
+	require.NoError(t, err)
+	assert.Len(t, first.Issues, 100)
+	assert.Equal(t, 150, first.Total)
+	assert.Equal(t, 2, first.NextPage)
+
+	second, err := c.SearchIssues("is:open", first.NextPage)
+	require.NoError(t, err)
+	assert.Len(t, second.Issues, 50)
+	assert.Equal(t, 101, second.Issues[0].Number)
+	assert.Zero(t, second.NextPage)
+}
+
+func Test_GitHubClient_SearchIssuesStopsAtSearchLimit(t *testing.T) {
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, searchPageJSON(2500, 901, 100))
+	})
+
+	page, err := c.SearchIssues("is:open", 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2500, page.Total)
+	assert.Zero(t, page.NextPage, "search results stop after 1000")
+}
+
+func Test_GitHubClient_CloseIssueAndLabels(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		var body map[string]any
+		if json.NewDecoder(r.Body).Decode(&body) == nil {
+			bodies = append(bodies, body)
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		io.WriteString(w, `{}`)
+	})
+
+	require.NoError(t, c.CloseIssue("simonbrundin/ai", 42))
+	require.NoError(t, c.ReopenIssue("simonbrundin/ai", 42))
+	require.NoError(t, c.AddLabels("simonbrundin/ai", 42, "tester"))
+	require.NoError(t, c.RemoveLabel("simonbrundin/ai", 42, "user test"))
+
+	assert.Equal(t, []string{
+		"PATCH /repos/simonbrundin/ai/issues/42",
+		"PATCH /repos/simonbrundin/ai/issues/42",
+		"POST /repos/simonbrundin/ai/issues/42/labels",
+		"DELETE /repos/simonbrundin/ai/issues/42/labels/user%20test",
+	}, requests)
+	require.Len(t, bodies, 3)
+	assert.Equal(t, "closed", bodies[0]["state"])
+	assert.Equal(t, "open", bodies[1]["state"])
+	assert.Equal(t, []any{"tester"}, bodies[2]["labels"])
+}
+
+func Test_GitHubClient_ListUserRepos(t *testing.T) {
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user/repos", r.URL.Path)
+		assert.Equal(t, "owner,organization_member,collaborator", r.URL.Query().Get("affiliation"))
+		io.WriteString(w, `[{"full_name": "simonbrundin/ai"}, {"full_name": "simonbrundin/web", "archived": true}]`)
+	})
+
+	repos, err := c.ListUserRepos()
+
+	require.NoError(t, err)
+	assert.Equal(t, []github.Repo{{FullName: "simonbrundin/ai"}, {FullName: "simonbrundin/web", Archived: true}}, repos)
+}
+
+func Test_GitHubClient_ListUserReposFollowsAllPages(t *testing.T) {
+	var pages []string
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		n := 100
+		if page == "3" {
+			n = 5
+		}
+		var repos []string
+		for i := 0; i < n; i++ {
+			repos = append(repos, fmt.Sprintf(`{"full_name": "simonbrundin/repo-%s-%d"}`, page, i))
+		}
+		io.WriteString(w, "["+strings.Join(repos, ",")+"]")
+	})
+
+	repos, err := c.ListUserRepos()
+
+	require.NoError(t, err)
+	assert.Len(t, repos, 205)
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+}
+
+func Test_GitHubClient_ListLabelsFollowsAllPages(t *testing.T) {
+	var pages []string
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/simonbrundin/ai/labels", r.URL.Path)
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		n := 100
+		if page == "2" {
+			n = 3
+		}
+		var labels []string
+		for i := 0; i < n; i++ {
+			labels = append(labels, fmt.Sprintf(`{"name": "label-%s-%d"}`, page, i))
+		}
+		io.WriteString(w, "["+strings.Join(labels, ",")+"]")
+	})
+
+	labels, err := c.ListLabels("simonbrundin/ai")
+
+	require.NoError(t, err)
+	assert.Len(t, labels, 103)
+	assert.Equal(t, []string{"1", "2"}, pages)
+}
+
+func Test_GitHubClient_StructuredErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		body    string
+		want    error
+	}{
+		{"unauthorized", 401, nil, `{"message": "Bad credentials"}`, github.ErrNotAuthenticated},
+		{"not found", 404, nil, `{"message": "Not Found"}`, github.ErrNotFound},
+		{"primary rate limit", 403, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1760000000"},
+			`{"message": "API rate limit exceeded"}`, github.ErrRateLimited},
+		{"secondary rate limit", 429, nil, `{"message": "You have exceeded a secondary rate limit"}`, github.ErrRateLimited},
+		{"label exists", 422, nil, `{"message": "Validation Failed", "errors": [{"resource": "Label", "code": "already_exists", "field": "name"}]}`, github.ErrAlreadyExists},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+				io.WriteString(w, tc.body)
+			})
+
+			err := c.CreateLabel("simonbrundin/ai", github.Label{Name: "tester"})
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.want)
+			var apiErr *github.APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tc.status, apiErr.StatusCode)
+			assert.Contains(t, err.Error(), tc.want.Error())
+		})
+	}
+}
+
+func Test_GitHubClient_ForbiddenWithoutRateLimitIsNotRateLimited(t *testing.T) {
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message": "Resource not accessible by integration"}`)
+	})
+
+	_, err := c.ListLabels("simonbrundin/ai")
+
+	assert.NotErrorIs(t, err, github.ErrRateLimited)
+	assert.ErrorContains(t, err, "Resource not accessible by integration")
+}
+
+func Test_GitHubClient_NetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	c := github.NewClient("test-token")
+	c.BaseURL = srv.URL
+
+	_, err := c.SearchIssues("is:open", 1)
+
+	assert.ErrorIs(t, err, github.ErrNetwork)
+}
+
+func Test_GitHubClient_WithoutTokenIsNotAuthenticated(t *testing.T) {
+	called := false
+	c := newGitHubTestClient(t, func(w http.ResponseWriter, r *http.Request) { called = true })
+	c.Token = ""
+
+	_, err := c.ListUserRepos()
+
+	assert.ErrorIs(t, err, github.ErrNotAuthenticated)
+	assert.False(t, called, "no request should be sent without a token")
+}
+
+func Test_GitHubToken_PrefersEnvironment(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "env-token")
+
+	token, err := github.Token()
+
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", token)
+}
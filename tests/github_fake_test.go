@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"ai-tui/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the in-memory issue tracker used to drive the TUI offline
+// =============================================================================
+
+func newFakeTracker() *github.Fake {
+	return github.NewFake().
+		AddIssue(github.Issue{Repo: "simonbrundin/ai", Number: 42, Title: "Cache agents", Labels: []string{"tester"}}).
+		AddIssue(github.Issue{Repo: "simonbrundin/ai", Number: 43, Title: "Timeline", State: "closed"}).
+		AddIssue(github.Issue{Repo: "other/web", Number: 1, Title: "Landing page"})
+}
+
+func Test_FakeTracker_SearchFiltersByOwnerAndState(t *testing.T) {
+	page, err := newFakeTracker().SearchIssues("owner:simonbrundin is:open", 1)
+
+	require.NoError(t, err)
+	require.Len(t, page.Issues, 1)
+	assert.Equal(t, 42, page.Issues[0].Number)
+	assert.Equal(t, "open", page.Issues[0].State)
+}
+
+func Test_FakeTracker_SearchPages(t *testing.T) {
+	f := github.NewFake()
+	f.PageSize = 2
+	for n := 1; n <= 5; n++ {
+		f.AddIssue(github.Issue{Repo: "simonbrundin/ai", Number: n})
+	}
+
+	first, err := f.SearchIssues("is:open", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, first.Total)
+	assert.Equal(t, 2, first.NextPage)
+
+	last, err := f.SearchIssues("is:open", 3)
+	require.NoError(t, err)
+	require.Len(t, last.Issues, 1)
+	assert.Equal(t, 5, last.Issues[0].Number)
+	assert.Zero(t, last.NextPage)
+}
+
+func Test_FakeTracker_CloseAndReopen(t *testing.T) {
+	f := newFakeTracker()
+
+	require.NoError(t, f.CloseIssue("simonbrundin/ai", 42))
+	issue, _ := f.Issue("simonbrundin/ai", 42)
+	assert.Equal(t, "closed", issue.State)
+
+	require.NoError(t, f.ReopenIssue("simonbrundin/ai", 42))
+	issue, _ = f.Issue("simonbrundin/ai", 42)
+	assert.Equal(t, "open", issue.State)
+
+	assert.ErrorIs(t, f.CloseIssue("simonbrundin/ai", 999), github.ErrNotFound)
+}
+
+func Test_FakeTracker_Labels(t *testing.T) {
+	f := newFakeTracker()
+
+	require.NoError(t, f.AddLabels("simonbrundin/ai", 42, "implementation"))
+	require.NoError(t, f.RemoveLabel("simonbrundin/ai", 42, "tester"))
+	issue, _ := f.Issue("simonbrundin/ai", 42)
+	assert.Equal(t, []string{"implementation"}, issue.Labels)
+
+	assert.ErrorIs(t, f.RemoveLabel("simonbrundin/ai", 42, "tester"), github.ErrNotFound)
+	assert.ErrorIs(t, f.CreateLabel("simonbrundin/ai", github.Label{Name: "tester"}), github.ErrAlreadyExists)
+	require.NoError(t, f.CreateLabel("simonbrundin/ai", github.Label{Name: "docs"}))
+
+	labels, err := f.ListLabels("simonbrundin/ai")
+	require.NoError(t, err)
+	assert.Equal(t, []github.Label{{Name: "tester"}, {Name: "implementation"}, {Name: "docs"}}, labels)
+}
+
+func Test_FakeTracker_FailOn(t *testing.T) {
+	boom := errors.New("boom")
+	f := newFakeTracker().FailOn("ListUserRepos", boom)
+
+	_, err := f.ListUserRepos()
+	assert.ErrorIs(t, err, boom)
+
+	f.FailOn("ListUserRepos", nil)
+	repos, err := f.ListUserRepos()
+	require.NoError(t, err)
+	assert.Equal(t, []github.Repo{{FullName: "simonbrundin/ai"}, {FullName: "other/web"}}, repos)
+	assert.Equal(t, []string{"ListUserRepos", "ListUserRepos"}, f.Calls())
+}
@@ -64,6 +64,42 @@ func TestGodog(t *testing.T) {
 		}
 	})
 
+	t.Run("agent_tui", func(t *testing.T) {
+		agentOpts := *opts
+		agentOpts.Paths = []string{"agent_tui.feature"}
+		suite := godog.TestSuite{
+			Name:                 "agent tui features",
+			TestSuiteInitializer: func(ctx *godog.TestSuiteContext) {},
+			ScenarioInitializer: func(ctx *godog.ScenarioContext) {
+				steps.InitializeAgentTUIScenario(ctx)
+			},
+			Options: &agentOpts,
+		}
+
+		status := suite.Run()
+		if status != 0 {
+			t.Errorf("godog tests failed with status: %d", status)
+		}
+	})
+
+	t.Run("issue_tracker", func(t *testing.T) {
+		trackerOpts := *opts
+		trackerOpts.Paths = []string{"issue_tracker.feature"}
+		suite := godog.TestSuite{
+			Name:                 "issue tracker features",
+			TestSuiteInitializer: func(ctx *godog.TestSuiteContext) {},
+			ScenarioInitializer: func(ctx *godog.ScenarioContext) {
+				steps.InitializeAgentTUIScenario(ctx)
+			},
+			Options: &trackerOpts,
+		}
+
+		status := suite.Run()
+		if status != 0 {
+			t.Errorf("godog tests failed with status: %d", status)
+		}
+	})
+
 	t.Run("reload_error_handling", func(t *testing.T) {
 		suite := godog.TestSuite{
 			Name:                 "reload error handling features",
@@ -0,0 +1,569 @@
+Feature: Issue flows against an in-memory issue tracker
+  As a developer
+  I want the real TUI to close and label issues against a fake tracker
+  So that issue scenarios run offline end to end
+
+  Background:
+    Given the monitor runs with a scripted agent detector
+    And the issue tracker has these issues:
+      | repo            | number | title        | labels     |
+      | simonbrundin/ai | 42     | Cache agents | bug,tester |
+      | simonbrundin/ai | 43     | Add timeline |            |
+
+  Scenario: Issues from the tracker are listed
+    When the monitor refreshes
+    Then the screen shows "Cache agents"
+    And the screen shows "Add timeline"
+
+  Scenario: Confirming the done dialog closes the issue
+    When the monitor refreshes
+    And I press the "d" key
+    Then the screen shows "Bekräfta?"
+    When I press the "y" key
+    Then issue #42 in "simonbrundin/ai" is closed
+    And the screen does not show "Cache agents"
+    And the screen shows "Add timeline"
+
+  Scenario: Declining the done dialog keeps the issue open
+    When the monitor refreshes
+    And I press the "d" key
+    And I press the "n" key
+    Then issue #42 in "simonbrundin/ai" is open
+
+  Scenario: Closing fails with an error from the tracker
+    Given the issue tracker fails to close issues with "service unavailable"
+    When the monitor refreshes
+    And I press the "d" key
+    And I press the "y" key
+    Then the screen shows "failed to close issue: service unavailable"
+    And issue #42 in "simonbrundin/ai" is open
+
+  Scenario: Choosing a phase replaces the previous phase label
+    When the monitor refreshes
+    And I press the "p" key
+    Then the screen shows "6. Issue är i PR-fas"
+    When I press the "2" key
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+
+  Scenario: The phase dialog offers only the transitions of the repo's workflow
+    Given the issue tracker has these issues:
+      | repo     | number | title         | labels |
+      | acme/api | 7      | Rate limiting | design |
+    And the monitor is configured with:
+      """
+      github:
+        orgs: [acme]
+      workflows:
+        acme/api:
+          - {label: spike, next: [design]}
+          - {label: design, next: [build, spike]}
+          - {label: build, color: 0e8a16, description: Bygger, next: [review]}
+          - {label: review, next: [build]}
+      """
+    When the monitor refreshes
+    And I press the "p" key
+    Then the screen shows "1. Bygger"
+    And the screen shows "2. spike"
+    And the screen does not show "review"
+    When I press the "1" key
+    Then issue #7 in "acme/api" has the labels "build"
+
+  Scenario: An issue in a final phase cannot change phase
+    Given the monitor is configured with:
+      """
+      phases:
+        - {label: tester, next: [done]}
+        - {label: done}
+      """
+    And the issue tracker has these issues:
+      | repo            | number | title       | labels |
+      | simonbrundin/ai | 44     | Ship it     | done   |
+    When the monitor refreshes
+    And I press the "j" key 2 times
+    And I press the "p" key
+    Then the screen shows "issue #44 is in the final phase"
+
+  Scenario: The new issue dialog lists repos from the tracker
+    When the monitor refreshes
+    And I press the "n" key
+    Then the screen shows "Select repository:"
+    And the screen shows "> ai"
+
+  Scenario: Configured repos are listed in the new issue dialog
+    Given the monitor is configured with:
+      """
+      github:
+        repos: [friend/tools]
+      """
+    When the monitor refreshes
+    And I press the "n" key
+    Then the screen shows "ai"
+    And the screen shows "tools"
+
+  Scenario: Agent list keys are typed into the repo filter
+    Given the monitor is configured with:
+      """
+      github:
+        repos: [friend/tools]
+      """
+    When the monitor refreshes
+    And I press the "n" key
+    And I press the "s" key
+    And I press the "P" key
+    And I press the "K" key
+    Then the screen shows "Filter: sPK"
+
+  Scenario: Repo listing errors are shown in the new issue dialog
+    Given the issue tracker fails to list repos with "unauthorized"
+    When the monitor refreshes
+    And I press the "n" key
+    Then the screen shows "failed to list repos: unauthorized"
+
+  Scenario: Issues beyond the first page load while moving down the list
+    Given the issue tracker returns 20 issues per page
+    And the issue tracker has 45 open issues in "simonbrundin/web"
+    When the monitor refreshes
+    Then the screen shows "showing 20 of 47"
+    When I press the "j" key 10 times
+    Then the screen shows "showing 40 of 47"
+    When I press the "j" key 25 times
+    Then the screen shows "showing 47 of 47"
+
+  Scenario: Refreshing keeps the pages already loaded
+    Given the issue tracker returns 20 issues per page
+    And the issue tracker has 45 open issues in "simonbrundin/web"
+    When the monitor refreshes
+    And I press the "j" key 10 times
+    Then the screen shows "showing 40 of 47"
+    When the monitor refreshes
+    Then the screen shows "showing 40 of 47"
+
+  Scenario: Configured orgs and repos are searched and excluded repos are hidden
+    Given the issue tracker has these issues:
+      | repo            | number | title          | labels |
+      | acme/api        | 1      | Rate limiting  |        |
+      | acme/legacy-web | 2      | Old login page |        |
+      | friend/tools    | 3      | Shared linter  |        |
+      | stranger/app    | 4      | Not ours       |        |
+    And the monitor is configured with:
+      """
+      github:
+        owners: [simonbrundin]
+        orgs: [acme]
+        repos: [friend/tools]
+        exclude: ["acme/legacy-*"]
+      """
+    When the monitor refreshes
+    Then the screen shows "Cache agents"
+    And the screen shows "Rate limiting"
+    And the screen shows "Shared linter"
+    And the screen does not show "Old login page"
+    And the screen does not show "Not ours"
+    And the screen shows "showing 4 of 4"
+
+  Scenario: The command dialog offers the default commands
+    When the monitor refreshes
+    And I press the "enter" key
+    Then the screen shows "Välj kommando för issue #42"
+    And the screen shows "5. Skapa PR"
+    And the screen shows "1-5:"
+
+  Scenario: Long titles with multibyte characters are cut by display width
+    Given the issue tracker has these issues:
+      | repo            | number | title                                                | labels |
+      | simonbrundin/ai | 41     | Rita om ramen ╭──────────────────────╮ i förhandsvyn |        |
+    When the monitor refreshes
+    And I press the "enter" key
+    Then the screen shows "Välj kommando för issue #41"
+    And the screen shows "Rita om ramen ╭────────────..."
+
+  Scenario: The command dialog lists the configured commands
+    Given the monitor is configured with:
+      """
+      agents:
+        claude:
+          binary: claude
+      commands:
+        - label: Planera
+          prompt: /plan {number}
+        - label: Granska
+          prompt: "Review {repo}#{number}: {title}"
+          agent: claude
+      """
+    When the monitor refreshes
+    And I press the "enter" key
+    And I press the "down" key
+    Then the screen shows "1. Planera"
+    And the screen shows "> 2. Granska"
+    And the screen shows "1-2:"
+    And the screen does not show "Skriv tester"
+    When I press the "3" key
+    Then the screen shows "Välj kommando för issue #42"
+
+  Scenario: A command that advances moves the issue on when its agent succeeds
+    Given the monitor is configured with:
+      """
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    And I switch to the agents tab
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+    And the screen shows "issue #42 moved to implementation"
+
+  Scenario: A failed agent leaves the phase unchanged
+    Given the monitor is configured with:
+      """
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 1
+    And the background watcher scans
+    And I switch to the agents tab
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,tester"
+    And the screen shows "agent exited with status 1, phase unchanged"
+
+  Scenario: An agent whose remote differs in case from its issue still moves it on
+    Given the monitor is configured with:
+      """
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | SimonBrundin/AI | 42    | /tdd    |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | SimonBrundin/AI | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+
+  Scenario: An agent whose remote names no GitHub repo is matched on its checkout
+    Given the monitor is configured with:
+      """
+      local:
+        paths:
+          simonbrundin/ai: /home/user/ai
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir       | state | repo | issue | command |
+      | OpenCode | 1001 | /home/user/ai/cmd | busy  |      | 42    | /tdd    |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir       | state | repo | issue | command |
+      | OpenCode | 1001 | /home/user/ai/cmd | busy  |      | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+
+  Scenario: An exit status written after the agent is gone is read on the next scan
+    Given the monitor is configured with:
+      """
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the background watcher scans
+    And the background watcher scans
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,tester"
+    When "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+
+  Scenario: A stopped agent leaves the phase unchanged
+    Given the monitor is configured with:
+      """
+      commands:
+        - label: Skriv tester
+          prompt: /tdd {number}
+          phase: tester
+          advance: true
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 143
+    And the background watcher scans
+    And I switch to the agents tab
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,tester"
+    And the screen shows "issue #42: agent stopped, phase unchanged"
+
+  Scenario: Agents of commands that do not advance leave the phase alone
+    Given the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then issue #42 in "simonbrundin/ai" has the labels "bug,tester"
+
+  Scenario: The command dialog launches the agent and sets the command's phase
+    When the monitor refreshes
+    And I press the "enter" key
+    And I press the "2" key
+    Then an agent was launched in window "opencode-/implement-42"
+    And issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+
+  Scenario: A pipeline launches each step after the previous agent succeeds
+    Given the monitor is configured with:
+      """
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I press the "P" key
+    Then the screen shows "Välj pipeline för issue #42"
+    And the screen shows "/tdd → /implement"
+    When I press the "1" key
+    Then an agent was launched in window "opencode-/tdd-42"
+    And no agent was launched in window "opencode-/implement-42"
+    And the pipeline of issue #42 in "simonbrundin/ai" is running at "/tdd"
+    And the screen shows "⛓ 1/2 /tdd"
+    When the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then an agent was launched in window "opencode-/implement-42"
+    And issue #42 in "simonbrundin/ai" has the labels "bug,implementation"
+    And the pipeline of issue #42 in "simonbrundin/ai" is running at "/implement"
+
+  Scenario: A step that exits before the first scan still moves its pipeline on
+    Given the monitor is configured with:
+      """
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    When the monitor refreshes
+    And I press the "P" key
+    And I press the "1" key
+    Then an agent was launched in window "opencode-/tdd-42"
+    When "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the background watcher scans
+    Then an agent was launched in window "opencode-/implement-42"
+    And the pipeline of issue #42 in "simonbrundin/ai" is running at "/implement"
+
+  Scenario: A failed step stops the pipeline and can be resumed
+    Given the monitor is configured with:
+      """
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I press the "P" key
+    And I press the "1" key
+    And the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 2
+    And the background watcher scans
+    Then no agent was launched in window "opencode-/implement-42"
+    And the pipeline of issue #42 in "simonbrundin/ai" is stopped at "/tdd"
+    And the screen shows "⛓ stopped at /tdd"
+    When I press the "P" key
+    Then the screen shows "Test first (fortsätt vid /tdd)"
+    When I press the "1" key
+    Then the pipeline of issue #42 in "simonbrundin/ai" is running at "/tdd"
+
+  Scenario: A stopped step stops the pipeline as stopped rather than failed
+    Given the monitor is configured with:
+      """
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I press the "P" key
+    And I press the "1" key
+    And the background watcher scans
+    And "/tdd" for issue #42 in "simonbrundin/ai" exited with status 130
+    And the background watcher scans
+    Then no agent was launched in window "opencode-/implement-42"
+    And the pipeline of issue #42 in "simonbrundin/ai" is stopped at "/tdd"
+    When I switch to the agents tab
+    Then the screen shows "Test first stopped, /tdd was stopped"
+
+  Scenario: A pipeline picks up where it was after a restart
+    Given the monitor is configured with:
+      """
+      pipelines:
+        - name: Test first
+          steps: [/tdd]
+      """
+    When the monitor refreshes
+    And I press the "P" key
+    And I press the "1" key
+    Then the pipeline of issue #42 in "simonbrundin/ai" is running at "/tdd"
+    When "/tdd" for issue #42 in "simonbrundin/ai" exited with status 0
+    And the monitor restarts
+    Then the pipeline of issue #42 in "simonbrundin/ai" is done
+
+  Scenario: Commands beyond the agent limit wait in the queue until an agent exits
+    Given the monitor is configured with:
+      """
+      limits:
+        max_agents: 1
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I press the "j" key
+    And I press the "enter" key
+    And I press the "1" key
+    Then no agent was launched in window "opencode-/tdd-43"
+    And the screen shows "⏳ /tdd queued"
+    When I switch to the queue tab
+    Then the screen shows "Queue (1)"
+    And the screen shows "1 running, limit 1"
+    And the screen shows "simonbrundin/ai #43 /tdd"
+    When the background watcher scans
+    Then an agent was launched in window "opencode-/tdd-43"
+    And the screen shows "starting"
+    And the screen does not show "Queue (1)"
+
+  Scenario: Pipeline steps wait in the queue like other commands
+    Given the monitor is configured with:
+      """
+      limits:
+        max_agents: 1
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    And on the next refresh the detector reports no agents
+    When the monitor refreshes
+    And I press the "j" key
+    And I press the "P" key
+    And I press the "1" key
+    Then no agent was launched in window "opencode-/tdd-43"
+    And the pipeline of issue #43 in "simonbrundin/ai" is running at "/tdd"
+    And the screen shows "⏳ /tdd queued"
+    When the background watcher scans
+    Then an agent was launched in window "opencode-/tdd-43"
+
+  Scenario: Cancelling a queued pipeline step drops the pipeline
+    Given the monitor is configured with:
+      """
+      limits:
+        max_agents: 1
+      pipelines:
+        - name: Test first
+          steps: [/tdd, /implement]
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    When the monitor refreshes
+    And I press the "j" key
+    And I press the "P" key
+    And I press the "1" key
+    And I switch to the queue tab
+    And I press the "x" key
+    Then the pipeline of issue #43 in "simonbrundin/ai" is cancelled
+    And the screen shows "Test first stopped, /tdd was cancelled"
+    And no agent was launched in window "opencode-/tdd-43"
+
+  Scenario: Queued commands can be reordered, cancelled and started right away
+    Given the monitor is configured with:
+      """
+      limits:
+        max_agents: 1
+      """
+    And the detector reports these agents:
+      | kind     | pid  | working_dir   | state | repo            | issue | command |
+      | OpenCode | 1001 | /home/user/ai | busy  | simonbrundin/ai | 42    | /tdd    |
+    When the monitor refreshes
+    And I press the "enter" key
+    And I press the "3" key
+    And I press the "j" key
+    And I press the "enter" key
+    And I press the "1" key
+    And I switch to the queue tab
+    Then the screen shows "#42 /refactor" before "#43 /tdd"
+    When I press the "j" key
+    And I press the "K" key
+    Then the screen shows "#43 /tdd" before "#42 /refactor"
+    When I press the "x" key
+    Then the screen does not show "#43 /tdd"
+    And the screen shows "Queue (1)"
+    When I press the "enter" key
+    Then an agent was launched in window "opencode-/refactor-42"
+    And no agent was launched in window "opencode-/tdd-43"
+
+  Scenario: Agents run in a worktree per issue that the done dialog can remove
+    Given "simonbrundin/ai" is checked out in a git repository with worktrees enabled
+    When the monitor refreshes
+    And I press the "enter" key
+    And I press the "1" key
+    Then the agent in window "opencode-/tdd-42" was launched in the worktree "issue-42-cache-agents"
+    When I press the "d" key
+    Then the screen shows "ta bort worktree"
+    And the screen shows "issue-42-cache-agents"
+    When I press the "w" key
+    Then issue #42 in "simonbrundin/ai" is closed
+    And the worktree "issue-42-cache-agents" was removed
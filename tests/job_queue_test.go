@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"ai-tui/config"
+	"ai-tui/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the job queue behind the command dialog
+// =============================================================================
+
+func jobKeys(jobs []queue.Job) []string {
+	keys := make([]string, len(jobs))
+	for i, j := range jobs {
+		keys[i] = j.Repo + " " + j.Command
+	}
+	return keys
+}
+
+func Test_Queue_DispatchRespectsGlobalAndRepoLimits(t *testing.T) {
+	q := queue.New()
+	q.Push("acme/api", 1, "", "/tdd")
+	q.Push("acme/api", 2, "", "/tdd")
+	q.Push("acme/web", 3, "", "/tdd")
+	q.Push("acme/web", 4, "", "/tdd")
+	limits := config.Limits{MaxAgents: 3, MaxPerRepo: 1}
+	running := []agent.Agent{{PID: 1, Repo: "acme/api", Issue: 9, Command: "/pr"}}
+
+	started := q.Dispatch(limits, running)
+
+	assert.Equal(t, []string{"acme/web /tdd"}, jobKeys(started), "the repo limit skips acme/api but not acme/web")
+	assert.Equal(t, 3, q.Waiting())
+
+	started = q.Dispatch(limits, nil)
+	assert.Equal(t, []string{"acme/api /tdd"}, jobKeys(started), "the started acme/web job still counts")
+	assert.Equal(t, 1, started[0].Issue)
+}
+
+func Test_Queue_StartedJobsCountUntilTheirAgentAppears(t *testing.T) {
+	clock, advance := fakeClock(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	q := queue.New()
+	q.SetClock(clock)
+	q.Push("acme/api", 1, "", "/tdd")
+	q.Push("acme/api", 2, "", "/tdd")
+	limits := config.Limits{MaxAgents: 1}
+
+	require.Len(t, q.Dispatch(limits, nil), 1)
+	assert.Empty(t, q.Dispatch(limits, nil), "the first job is still starting")
+
+	agents := []agent.Agent{{PID: 1, Repo: "acme/api", Issue: 1, Command: "/tdd"}}
+	assert.Empty(t, q.Dispatch(limits, agents), "its agent now holds the slot")
+	assert.Len(t, q.Jobs(), 1, "the detected job leaves the queue")
+
+	assert.Len(t, q.Dispatch(limits, nil), 1, "the agent exited")
+	advance(queue.StartTimeout + time.Second)
+	q.Dispatch(limits, nil)
+	assert.Empty(t, q.Jobs(), "a job whose agent never appears stops counting")
+}
+
+func Test_Queue_ReorderCancelAndPromote(t *testing.T) {
+	q := queue.New()
+	a := q.Push("acme/api", 1, "", "/tdd")
+	b := q.Push("acme/api", 2, "", "/implement")
+	c := q.Push("acme/api", 3, "", "/pr")
+
+	require.NoError(t, q.Move(c.ID, -5))
+	assert.Equal(t, []int{3, 1, 2}, issuesOf(q.Jobs()))
+	require.NoError(t, q.Move(c.ID, 1))
+	assert.Equal(t, []int{1, 3, 2}, issuesOf(q.Jobs()))
+
+	require.NoError(t, q.Cancel(a.ID))
+	assert.ErrorIs(t, q.Cancel(a.ID), queue.ErrNoJob)
+
+	promoted, err := q.Promote(b.ID)
+	require.NoError(t, err)
+	assert.False(t, promoted.Waiting())
+	assert.Equal(t, []int{3, 2}, issuesOf(q.Jobs()), "started jobs follow the waiting ones")
+	assert.ErrorIs(t, q.Cancel(b.ID), queue.ErrStarted)
+	assert.ErrorIs(t, q.Move(b.ID, -1), queue.ErrStarted)
+
+	q.Drop(b.ID)
+	assert.Equal(t, []int{3}, issuesOf(q.Jobs()))
+}
+
+func issuesOf(jobs []queue.Job) []int {
+	issues := make([]int, len(jobs))
+	for i, j := range jobs {
+		issues[i] = j.Issue
+	}
+	return issues
+}
+
+func Test_Queue_PipelineStepsShareTheQueueOrder(t *testing.T) {
+	q := queue.New()
+	q.Push("acme/api", 1, "", "/tdd")
+	step := q.PushStep("acme/api", 2, "", "/implement")
+
+	started := q.Dispatch(config.Limits{MaxAgents: 1}, nil)
+
+	require.Len(t, started, 1)
+	assert.False(t, started[0].Step)
+	assert.True(t, step.Step)
+	assert.Equal(t, []string{"acme/api /implement", "acme/api /tdd"}, jobKeys(q.Jobs()), "the step waits behind the started command")
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-tui/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the per-issue pipeline progress store
+// =============================================================================
+
+func Test_PipelineStore_AdvancesThroughTheSteps(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	clock, advance := fakeClock(start)
+	store := pipeline.NewStore(filepath.Join(t.TempDir(), "pipelines.json"))
+	store.SetClock(clock)
+
+	run, err := store.Start("simonbrundin/ai", 42, "Hela flödet", []string{"/tdd", "/implement"})
+	require.NoError(t, err)
+	assert.Equal(t, "/tdd", run.Command())
+	assert.Equal(t, start, run.Updated)
+
+	_, err = store.Start("simonbrundin/ai", 42, "Hela flödet", []string{"/tdd"})
+	assert.ErrorIs(t, err, pipeline.ErrRunning)
+
+	advance(time.Minute)
+	run, err = store.Advance("simonbrundin/ai", 42)
+	require.NoError(t, err)
+	assert.Equal(t, "/implement", run.Command())
+	assert.Equal(t, pipeline.StatusRunning, run.Status)
+	assert.Equal(t, start.Add(time.Minute), run.Updated)
+
+	run, err = store.Advance("simonbrundin/ai", 42)
+	require.NoError(t, err)
+	assert.Equal(t, pipeline.StatusDone, run.Status)
+	assert.Empty(t, run.Command())
+
+	_, err = store.Advance("simonbrundin/ai", 42)
+	assert.ErrorIs(t, err, pipeline.ErrNoRun, "a finished run no longer advances")
+}
+
+func Test_PipelineStore_ResumesAFailedRunAtTheFailedStep(t *testing.T) {
+	store := pipeline.NewStore(filepath.Join(t.TempDir(), "pipelines.json"))
+	steps := []string{"/tdd", "/implement", "/pr"}
+
+	_, err := store.Start("simonbrundin/ai", 42, "Hela flödet", steps)
+	require.NoError(t, err)
+	_, err = store.Advance("simonbrundin/ai", 42)
+	require.NoError(t, err)
+	run, err := store.Fail("simonbrundin/ai", 42, "/implement exited with status 1")
+	require.NoError(t, err)
+	assert.Equal(t, pipeline.StatusFailed, run.Status)
+
+	run, err = store.Start("simonbrundin/ai", 42, "Hela flödet", steps)
+	require.NoError(t, err)
+	assert.Equal(t, "/implement", run.Command())
+	assert.Empty(t, run.Error)
+
+	_, err = store.Fail("simonbrundin/ai", 42, "stopped")
+	require.NoError(t, err)
+	run, err = store.Start("simonbrundin/ai", 42, "Bara tester", []string{"/tdd"})
+	require.NoError(t, err)
+	assert.Equal(t, "/tdd", run.Command(), "another pipeline starts over")
+}
+
+func Test_PipelineStore_PersistsRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "pipelines.json")
+	store := pipeline.NewStore(path)
+
+	_, err := store.Start("simonbrundin/ai", 42, "Hela flödet", []string{"/tdd", "/implement"})
+	require.NoError(t, err)
+	_, err = store.Start("acme/api", 7, "Hela flödet", []string{"/tdd"})
+	require.NoError(t, err)
+	_, err = store.Advance("simonbrundin/ai", 42)
+	require.NoError(t, err)
+
+	runs, err := pipeline.NewStore(path).Runs()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "acme/api#7", runs[0].Key())
+	assert.Equal(t, "/implement", runs[1].Command())
+
+	require.NoError(t, store.Remove("acme/api", 7))
+	runs, err = pipeline.NewStore(path).Runs()
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
+
+func Test_PipelineStore_MissingFileHasNoRuns(t *testing.T) {
+	runs, err := pipeline.NewStore(filepath.Join(t.TempDir(), "pipelines.json")).Runs()
+
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
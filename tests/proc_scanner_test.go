@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for the native /proc scanner, run against a fake proc tree on disk
+// =============================================================================
+
+type fakeProc struct {
+	pid, ppid  int
+	comm       string
+	argv       []string
+	cwd        string
+	cpuTicks   int
+	startTicks int
+	rssPages   int
+}
+
+func writeFakeProcTree(t *testing.T, bootTime int64, procs ...fakeProc) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "stat"),
+		[]byte(fmt.Sprintf("cpu  1 2 3 4\nbtime %d\nprocesses 10\n", bootTime)), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "self"), 0o755))
+
+	for _, p := range procs {
+		dir := filepath.Join(root, fmt.Sprint(p.pid))
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		stat := fmt.Sprintf("%d (%s) S %d %d %d 34816 %d 4194560 100 0 0 0 %d 0 0 0 20 0 1 0 %d 1000000 %d 18446744073709551615",
+			p.pid, p.comm, p.ppid, p.pid, p.pid, p.pid, p.cpuTicks, p.startTicks, p.rssPages)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644))
+		cmdline := strings.Join(p.argv, "\x00")
+		if cmdline != "" {
+			cmdline += "\x00"
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644))
+		if p.cwd != "" {
+			require.NoError(t, os.Symlink(p.cwd, filepath.Join(dir, "cwd")))
+		}
+	}
+	return root
+}
+
+func Test_ProcScanner_ReadsProcessFields(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 100, ppid: 1, comm: "opencode", argv: []string{"opencode", "--prompt", "/tdd 42"},
+			cwd: "/home/user/repos/ai", cpuTicks: 250, startTicks: 500, rssPages: 10},
+	)
+	scanner := agent.NewProcScanner(root)
+	scanner.PageSize = 4096
+
+	procs, err := scanner.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, procs, 1)
+	p := procs[0]
+	assert.Equal(t, 100, p.PID)
+	assert.Equal(t, 1, p.PPID)
+	assert.Equal(t, []string{"opencode", "--prompt", "/tdd 42"}, p.Cmdline)
+	assert.Equal(t, "/home/user/repos/ai", p.Cwd)
+	assert.Equal(t, uint64(250), p.CPUTicks)
+	assert.Equal(t, int64(40960), p.RSS)
+	assert.Equal(t, time.Unix(1700000005, 0), p.StartTime)
+}
+
+func Test_ProcScanner_HandlesCommWithSpacesAndParens(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 7, ppid: 3, comm: "tmux: server (1)", argv: []string{"tmux"}},
+	)
+
+	procs, err := agent.NewProcScanner(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, procs, 1)
+	assert.Equal(t, 3, procs[0].PPID)
+}
+
+func Test_ProcScanner_SkipsNonNumericAndBrokenEntries(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 10, ppid: 1, comm: "bash", argv: []string{"bash"}},
+	)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "11"), 0o755))
+
+	procs, err := agent.NewProcScanner(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, procs, 1)
+	assert.Equal(t, 10, procs[0].PID)
+}
+
+func Test_ProcScanner_MissingRootReturnsError(t *testing.T) {
+	_, err := agent.NewProcScanner(filepath.Join(t.TempDir(), "missing")).Scan()
+
+	assert.Error(t, err)
+}
+
+func Test_FindAgents_FromFakeProcTree(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 100, ppid: 1, comm: "opencode", argv: []string{"opencode"}, cwd: "/repos/ai"},
+		fakeProc{pid: 200, ppid: 1, comm: "aider", argv: []string{"python3", "-m", "aider"}, cwd: "/repos/web"},
+		fakeProc{pid: 300, ppid: 1, comm: "vim", argv: []string{"vim", "opencode.json"}, cwd: "/repos/ai"},
+		fakeProc{pid: 400, ppid: 1, comm: "kworker/0:1", argv: nil},
+	)
+	procs, err := agent.NewProcScanner(root).Scan()
+	require.NoError(t, err)
+
+	agents := agent.FindAgents(procs)
+
+	require.Len(t, agents, 2)
+	assert.Equal(t, "OpenCode", agents[0].Name)
+	assert.Equal(t, "/repos/ai", agents[0].WorkingDir)
+	assert.Equal(t, "aider", agents[1].Name)
+	assert.Equal(t, "/repos/web", agents[1].WorkingDir)
+}
+
+func Test_FindAgents_ReportsOnlyRootAgentProcesses(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 100, ppid: 1, comm: "opencode-secure", argv: []string{"/bin/bash", "/home/user/bin/opencode-secure", "--prompt", "/tdd 42"}, cwd: "/repos/ai", rssPages: 1},
+		fakeProc{pid: 101, ppid: 100, comm: "opencode", argv: []string{"opencode", "--prompt", "/tdd 42"}, cwd: "/repos/ai", rssPages: 2},
+		fakeProc{pid: 102, ppid: 101, comm: "node", argv: []string{"node", "/home/user/.cache/opencode/worker.js"}, cwd: "/repos/ai"},
+		fakeProc{pid: 103, ppid: 101, comm: "gopls", argv: []string{"gopls"}, cwd: "/repos/ai"},
+	)
+	scanner := agent.NewProcScanner(root)
+	scanner.PageSize = 4096
+	procs, err := scanner.Scan()
+	require.NoError(t, err)
+
+	agents := agent.FindAgents(procs)
+
+	require.Len(t, agents, 1)
+	assert.Equal(t, int64(3*4096), agents[0].RSS)
+	assert.Equal(t, 100, agents[0].PID)
+	assert.Equal(t, "/tdd 42", agents[0].Metadata["prompt"])
+	assert.Equal(t, time.Unix(1700000000, 0), agents[0].StartTime)
+	var subPIDs []int
+	for _, p := range agents[0].SubProcesses {
+		subPIDs = append(subPIDs, p.PID)
+	}
+	assert.Equal(t, []int{101, 102, 103}, subPIDs)
+}
+
+func Test_FindAgents_KeepsSeparateSessionsInSameRepo(t *testing.T) {
+	root := writeFakeProcTree(t, 1700000000,
+		fakeProc{pid: 100, ppid: 50, comm: "opencode", argv: []string{"opencode"}, cwd: "/repos/ai"},
+		fakeProc{pid: 200, ppid: 60, comm: "opencode", argv: []string{"opencode"}, cwd: "/repos/ai"},
+		fakeProc{pid: 50, ppid: 1, comm: "zsh", argv: []string{"-zsh"}, cwd: "/repos/ai"},
+		fakeProc{pid: 60, ppid: 1, comm: "zsh", argv: []string{"-zsh"}, cwd: "/repos/ai"},
+	)
+	procs, err := agent.NewProcScanner(root).Scan()
+	require.NoError(t, err)
+
+	agents := agent.FindAgents(procs)
+
+	assert.Len(t, agents, 2)
+}
@@ -0,0 +1,469 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ai-tui/agent"
+	"ai-tui/config"
+	"ai-tui/github"
+	"ai-tui/history"
+	"ai-tui/pipeline"
+	"ai-tui/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cucumber/godog"
+)
+
+// AgentTUIState drives the real TUI model against a scripted detector and an
+// in-memory issue tracker
+type AgentTUIState struct {
+	Model    tea.Model
+	Detector *agent.ScriptedDetector
+	Tracker  *github.Fake
+	// Watcher scans the detector in place of the model's background watcher,
+	// which is disabled so scenarios control when scans happen
+	Watcher *agent.Watcher
+	// HistoryDir holds the history file of scenarios that record history
+	HistoryDir string
+	// StateDir receives the exit status of launched agents and the pipeline
+	// state
+	StateDir string
+	// Config is the configuration of the last "configured with" step
+	Config   config.Config
+	Launcher *recordingLauncher
+	// Checkout is the git repository of scenarios that use worktrees
+	Checkout string
+}
+
+// recordingLauncher records launches instead of opening tmux windows
+type recordingLauncher struct {
+	launches []tui.Launch
+	err      error
+}
+
+func (l *recordingLauncher) Launch(launch tui.Launch) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.launches = append(l.launches, launch)
+	return nil
+}
+
+// newModel replaces the model with one built from opts, filling in the
+// scripted detector, the fake tracker and the scenario's state dir
+func (s *AgentTUIState) newModel(opts tui.Options) {
+	opts.Detector = s.Detector
+	opts.Tracker = s.Tracker
+	opts.ScanInterval = -1
+	opts.StateDir = s.StateDir
+	opts.Launcher = s.Launcher
+	s.Model = tui.New(opts)
+	s.send(tea.WindowSizeMsg{Width: 120, Height: 40})
+}
+
+// send feeds a message to the model and returns the command it produced
+func (s *AgentTUIState) send(msg tea.Msg) tea.Cmd {
+	model, cmd := s.Model.Update(msg)
+	s.Model = model
+	return cmd
+}
+
+func (s *AgentTUIState) key(k string) tea.Cmd {
+	return s.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+}
+
+// run executes cmd and feeds the resulting messages back to the model,
+// expanding batches
+func (s *AgentTUIState) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			s.run(c)
+		}
+		return
+	}
+	s.run(s.send(msg))
+}
+
+// InitializeAgentTUIScenario sets up the step definitions that drive the TUI
+func InitializeAgentTUIScenario(ctx *godog.ScenarioContext) {
+	state := &AgentTUIState{}
+
+	ctx.BeforeScenario(func(sc *godog.Scenario) {
+		state = &AgentTUIState{}
+	})
+
+	ctx.After(func(c context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		if state.HistoryDir != "" {
+			os.RemoveAll(state.HistoryDir)
+		}
+		if state.StateDir != "" {
+			os.RemoveAll(state.StateDir)
+		}
+		return c, err
+	})
+
+	ctx.Step(`^the monitor runs with a scripted agent detector$`, func() error {
+		dir, err := os.MkdirTemp("", "ai-tui-state-")
+		if err != nil {
+			return err
+		}
+		state.StateDir = dir
+		state.Detector = agent.NewScriptedDetector()
+		state.Watcher = agent.NewWatcher(state.Detector, 0)
+		state.Tracker = github.NewFake()
+		state.Launcher = &recordingLauncher{}
+		state.newModel(tui.Options{})
+		return nil
+	})
+
+	ctx.Step(`^the monitor records agent history$`, func() error {
+		dir, err := os.MkdirTemp("", "ai-tui-history-")
+		if err != nil {
+			return err
+		}
+		state.HistoryDir = dir
+		state.newModel(tui.Options{History: history.NewRecorder(filepath.Join(dir, "history.jsonl"))})
+		return nil
+	})
+
+	ctx.Step(`^the monitor is configured with:$`, func(doc *godog.DocString) error {
+		dir, err := os.MkdirTemp("", "ai-tui-config-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte(doc.Content), 0o644); err != nil {
+			return err
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		state.Config = cfg
+		state.newModel(tui.Options{Config: cfg})
+		return nil
+	})
+
+	scriptAgents := func(table *godog.Table) error {
+		agents, err := agentsFromTable(table)
+		if err != nil {
+			return err
+		}
+		state.Detector.Then(agents, nil)
+		return nil
+	}
+	ctx.Step(`^"([^"]*)" is checked out in a git repository with worktrees enabled$`, func(repo string) error {
+		_, name, _ := strings.Cut(repo, "/")
+		checkout := filepath.Join(state.StateDir, "repos", name)
+		if err := os.MkdirAll(checkout, 0o755); err != nil {
+			return err
+		}
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "main"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Initial commit"},
+		} {
+			if out, err := exec.Command("git", append([]string{"-C", checkout}, args...)...).CombinedOutput(); err != nil {
+				return fmt.Errorf("git %s: %w: %s", args[0], err, out)
+			}
+		}
+		state.Checkout = checkout
+		state.Config.Local.Paths = map[string]string{repo: checkout}
+		state.Config.Local.Worktrees = true
+		state.newModel(tui.Options{Config: state.Config})
+		return nil
+	})
+
+	ctx.Step(`^the detector reports these agents:$`, scriptAgents)
+	ctx.Step(`^on the next refresh the detector reports these agents:$`, scriptAgents)
+
+	ctx.Step(`^on the next refresh the detector reports no agents$`, func() error {
+		state.Detector.Then(nil, agent.ErrNoAgentsFound)
+		return nil
+	})
+
+	ctx.Step(`^the detector fails with "([^"]*)"$`, func(msg string) error {
+		state.Detector.Then(nil, errors.New(msg))
+		return nil
+	})
+
+	ctx.Step(`^the monitor refreshes$`, func() error {
+		cmd := state.key("r")
+		if cmd == nil {
+			return fmt.Errorf("refresh key produced no command")
+		}
+		state.run(state.send(cmd()))
+		return nil
+	})
+
+	// The restarted monitor keeps the state dir, like a new process would
+	ctx.Step(`^the monitor restarts$`, func() error {
+		state.newModel(tui.Options{Config: state.Config})
+		state.run(state.send(state.key("r")()))
+		return nil
+	})
+
+	ctx.Step(`^"([^"]*)" for issue #(\d+) in "([^"]*)" exited with status (\d+)$`, func(command string, number int, repo string, status int) error {
+		dir := filepath.Join(state.StateDir, "exit")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		path := agent.ExitStatusPath(dir, repo, number, command)
+		return os.WriteFile(path, []byte(fmt.Sprintf("%d\n", status)), 0o644)
+	})
+
+	ctx.Step(`^the background watcher scans$`, func() error {
+		state.run(state.send(state.Watcher.Scan()))
+		return nil
+	})
+
+	ctx.Step(`^I switch to the agents tab$`, func() error {
+		state.key("2")
+		return nil
+	})
+
+	ctx.Step(`^I switch to the timeline tab$`, func() error {
+		state.key("3")
+		return nil
+	})
+
+	ctx.Step(`^I switch to the queue tab$`, func() error {
+		state.key("4")
+		return nil
+	})
+
+	ctx.Step(`^I press the "([^"]*)" key$`, func(k string) error {
+		state.run(state.key(k))
+		return nil
+	})
+
+	ctx.Step(`^I press the "([^"]*)" key (\d+) times$`, func(k string, n int) error {
+		for i := 0; i < n; i++ {
+			state.run(state.key(k))
+		}
+		return nil
+	})
+
+	ctx.Step(`^the issue tracker returns (\d+) issues per page$`, func(n int) error {
+		state.Tracker.PageSize = n
+		return nil
+	})
+
+	ctx.Step(`^the issue tracker has (\d+) open issues in "([^"]*)"$`, func(n int, repo string) error {
+		for number := 1; number <= n; number++ {
+			state.Tracker.AddIssue(github.Issue{Repo: repo, Number: number, Title: fmt.Sprintf("Issue %d", number)})
+		}
+		return nil
+	})
+
+	ctx.Step(`^the issue tracker has these issues:$`, func(table *godog.Table) error {
+		for i, row := range table.Rows {
+			if i == 0 {
+				continue
+			}
+			number, err := strconv.Atoi(row.Cells[1].Value)
+			if err != nil {
+				return fmt.Errorf("invalid issue number %q: %w", row.Cells[1].Value, err)
+			}
+			var labels []string
+			if v := row.Cells[3].Value; v != "" {
+				labels = strings.Split(v, ",")
+			}
+			state.Tracker.AddIssue(github.Issue{
+				Repo:   row.Cells[0].Value,
+				Number: number,
+				Title:  row.Cells[2].Value,
+				Labels: labels,
+			})
+		}
+		return nil
+	})
+
+	ctx.Step(`^the issue tracker fails to (close issues|add labels|list repos) with "([^"]*)"$`, func(action, msg string) error {
+		methods := map[string]string{
+			"close issues": "CloseIssue",
+			"add labels":   "AddLabels",
+			"list repos":   "ListUserRepos",
+		}
+		state.Tracker.FailOn(methods[action], errors.New(msg))
+		return nil
+	})
+
+	ctx.Step(`^issue #(\d+) in "([^"]*)" is (open|closed)$`, func(number int, repo, want string) error {
+		issue, ok := state.Tracker.Issue(repo, number)
+		if !ok {
+			return fmt.Errorf("issue #%d not found in %s", number, repo)
+		}
+		if issue.State != want {
+			return fmt.Errorf("expected issue #%d to be %s, got %s", number, want, issue.State)
+		}
+		return nil
+	})
+
+	ctx.Step(`^issue #(\d+) in "([^"]*)" has the labels "([^"]*)"$`, func(number int, repo, want string) error {
+		issue, ok := state.Tracker.Issue(repo, number)
+		if !ok {
+			return fmt.Errorf("issue #%d not found in %s", number, repo)
+		}
+		if got := strings.Join(issue.Labels, ","); got != want {
+			return fmt.Errorf("expected labels %q on issue #%d, got %q", want, number, got)
+		}
+		return nil
+	})
+
+	ctx.Step(`^an agent was launched in window "([^"]*)"$`, func(window string) error {
+		var windows []string
+		for _, l := range state.Launcher.launches {
+			if l.Window == window {
+				return nil
+			}
+			windows = append(windows, l.Window)
+		}
+		return fmt.Errorf("expected a launch in window %q, got %v", window, windows)
+	})
+
+	ctx.Step(`^no agent was launched in window "([^"]*)"$`, func(window string) error {
+		for _, l := range state.Launcher.launches {
+			if l.Window == window {
+				return fmt.Errorf("unexpected launch in window %q", window)
+			}
+		}
+		return nil
+	})
+
+	ctx.Step(`^the agent in window "([^"]*)" was launched in the worktree "([^"]*)"$`, func(window, branch string) error {
+		want := filepath.Join(agent.WorktreesDir(state.Checkout), branch)
+		for _, l := range state.Launcher.launches {
+			if l.Window != window {
+				continue
+			}
+			if l.Dir != want {
+				return fmt.Errorf("expected window %q in %s, got %s", window, want, l.Dir)
+			}
+			if _, err := os.Stat(filepath.Join(want, ".git")); err != nil {
+				return fmt.Errorf("worktree was not created: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("no launch in window %q", window)
+	})
+
+	ctx.Step(`^the worktree "([^"]*)" was removed$`, func(branch string) error {
+		dir := filepath.Join(agent.WorktreesDir(state.Checkout), branch)
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			return fmt.Errorf("expected %s to be removed", dir)
+		}
+		return nil
+	})
+
+	pipelineRun := func(repo string, number int) (pipeline.Run, error) {
+		// A fresh store reads what was persisted
+		runs, err := pipeline.NewStore(filepath.Join(state.StateDir, "pipelines.json")).Runs()
+		if err != nil {
+			return pipeline.Run{}, err
+		}
+		for _, r := range runs {
+			if r.Repo == repo && r.Issue == number {
+				return r, nil
+			}
+		}
+		return pipeline.Run{}, fmt.Errorf("no pipeline for issue #%d in %s", number, repo)
+	}
+
+	ctx.Step(`^the pipeline of issue #(\d+) in "([^"]*)" is (running|stopped) at "([^"]*)"$`, func(number int, repo, status, command string) error {
+		run, err := pipelineRun(repo, number)
+		if err != nil {
+			return err
+		}
+		want := pipeline.StatusRunning
+		if status == "stopped" {
+			want = pipeline.StatusFailed
+		}
+		if run.Status != want || run.Command() != command {
+			return fmt.Errorf("expected pipeline %s at %s, got %s at %s", want, command, run.Status, run.Command())
+		}
+		return nil
+	})
+
+	// Finished and cancelled runs are removed from the store
+	ctx.Step(`^the pipeline of issue #(\d+) in "([^"]*)" is (done|cancelled)$`, func(number int, repo, status string) error {
+		if run, err := pipelineRun(repo, number); err == nil {
+			return fmt.Errorf("expected pipeline to be %s, got %s at %s", status, run.Status, run.Command())
+		}
+		return nil
+	})
+
+	ctx.Step(`^the screen shows "([^"]*)"$`, func(text string) error {
+		if view := state.Model.View(); !strings.Contains(view, text) {
+			return fmt.Errorf("expected screen to contain %q, got:\n%s", text, view)
+		}
+		return nil
+	})
+
+	ctx.Step(`^the screen shows "([^"]*)" before "([^"]*)"$`, func(first, second string) error {
+		view := state.Model.View()
+		i, j := strings.Index(view, first), strings.Index(view, second)
+		if i < 0 || j < 0 || i > j {
+			return fmt.Errorf("expected %q before %q in view:\n%s", first, second, view)
+		}
+		return nil
+	})
+
+	ctx.Step(`^the screen does not show "([^"]*)"$`, func(text string) error {
+		if view := state.Model.View(); strings.Contains(view, text) {
+			return fmt.Errorf("expected screen not to contain %q, got:\n%s", text, view)
+		}
+		return nil
+	})
+}
+
+// agentsFromTable builds agents from a kind | pid | working_dir | state table,
+// optionally followed by repo | issue | command columns
+func agentsFromTable(table *godog.Table) ([]agent.Agent, error) {
+	states := map[string]agent.State{
+		"busy":    agent.StateBusy,
+		"idle":    agent.StateIdle,
+		"waiting": agent.StateWaiting,
+	}
+	var agents []agent.Agent
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		pid, err := strconv.Atoi(row.Cells[1].Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid %q: %w", row.Cells[1].Value, err)
+		}
+		st, ok := states[row.Cells[3].Value]
+		if !ok {
+			return nil, fmt.Errorf("unknown state %q", row.Cells[3].Value)
+		}
+		a := agent.Agent{
+			Name:       row.Cells[0].Value,
+			PID:        pid,
+			WorkingDir: row.Cells[2].Value,
+			State:      st,
+			IsActive:   st == agent.StateBusy,
+		}
+		if len(row.Cells) >= 7 {
+			issueNum, err := strconv.Atoi(row.Cells[5].Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid issue %q: %w", row.Cells[5].Value, err)
+			}
+			a.Repo, a.Issue, a.Command = row.Cells[4].Value, issueNum, row.Cells[6].Value
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"ai-tui/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// Tests for mapping agents to tmux sessions, windows and panes
+// =============================================================================
+
+func Test_ParsePanes_ReadsListPanesOutput(t *testing.T) {
+	output := "4100\tsimonbrundin-ai\t2\topencode-/tdd-42\t0\t%12\n" +
+		"4200\tmy session\t1\tmain\t1\t%13\n" +
+		"garbage line\n"
+
+	panes := agent.ParsePanes(output)
+
+	require.Len(t, panes, 2)
+	assert.Equal(t, agent.Pane{PID: 4100, Session: "simonbrundin-ai", WindowIndex: 2, Window: "opencode-/tdd-42", Index: 0, ID: "%12"}, panes[0])
+	assert.Equal(t, "my session", panes[1].Session)
+	assert.Equal(t, "%13", panes[1].Target())
+	assert.Equal(t, "simonbrundin-ai:opencode-/tdd-42.0", panes[0].String())
+}
+
+func Test_AttachPanes_MatchesPanePIDAndDescendants(t *testing.T) {
+	procs := []agent.Process{
+		{PID: 4100, PPID: 1, Cmdline: []string{"-zsh"}},
+		{PID: 4101, PPID: 4100, Cmdline: []string{"opencode"}},
+		{PID: 4200, PPID: 1, Cmdline: []string{"opencode"}},
+		{PID: 5000, PPID: 1, Cmdline: []string{"opencode"}},
+	}
+	panes := []agent.Pane{
+		{PID: 4100, Session: "ai", Window: "opencode-/tdd-42", ID: "%1"},
+		{PID: 4200, Session: "web", Window: "main", ID: "%2"},
+	}
+	agents := []agent.Agent{{PID: 4101}, {PID: 4200}, {PID: 5000}}
+
+	agents = agent.AttachPanes(agents, panes, agent.NewProcessTree(procs))
+
+	assert.Equal(t, "%1", agents[0].Pane.ID)
+	assert.Equal(t, "opencode-/tdd-42", agents[0].Pane.Window)
+	assert.Equal(t, "%2", agents[1].Pane.ID)
+	assert.False(t, agents[2].InPane())
+}
+
+func Test_LastLines_TrimsBlankTailAndKeepsLastN(t *testing.T) {
+	captured := "one\ntwo\nthree\nfour\n\n\n   \n"
+
+	assert.Equal(t, "three\nfour", agent.LastLines(captured, 2))
+	assert.Equal(t, "one\ntwo\nthree\nfour", agent.LastLines(captured, 10))
+	assert.Equal(t, "one\ntwo\nthree\nfour", agent.LastLines(captured, 0))
+}
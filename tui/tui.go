@@ -0,0 +1,3985 @@
+// Package tui implements the AI Monitor terminal UI.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-tui/agent"
+	"ai-tui/config"
+	"ai-tui/github"
+	"ai-tui/history"
+	"ai-tui/pipeline"
+	"ai-tui/queue"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	headerHeight = 2
+	footerHeight = 1
+	minWidth     = 80
+	minHeight    = 24
+)
+
+const (
+	loadMoreThreshold    = 10
+	issuePrefixWidth     = 10
+	issuePadding         = 2
+	issueMinTitleWidth   = 10
+	dialogWidth          = 50
+	dialogHeight         = 12
+	confirmTitleTruncate = 30
+)
+
+const (
+	commandDialogWidth  = 40
+	commandDialogHeight = 14
+)
+
+const (
+	newIssueDialogWidth  = 50
+	newIssueDialogHeight = 15
+)
+
+const (
+	phaseDialogWidth  = 40
+	phaseDialogHeight = 12
+)
+
+const (
+	previewInterval  = time.Second
+	previewMaxLines  = 200
+	previewMinHeight = 5
+)
+
+const gitSubjectTruncate = 40
+
+// timelineMaxSessions caps the sessions listed per repo on the Timeline tab
+const timelineMaxSessions = 10
+
+// maxQuickSelect is the number of dialog entries reachable with the 1-9 keys
+const maxQuickSelect = 9
+
+// exitStatusGrace is how long the exit status of an agent that is gone may
+// still be on its way: the shell writes it just after the agent exits
+const exitStatusGrace = 5 * time.Second
+
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("205"))
+
+	headerBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("235")).
+			Padding(0, 1)
+
+	tabActiveStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("205")).
+			Padding(0, 2).
+			Bold(true)
+
+	tabInactiveStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("241")).
+				Padding(0, 2)
+
+	footerBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("235")).
+			Padding(0, 1)
+
+	keyHintStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205"))
+
+	sectionTitleStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("212")).
+				Padding(1, 2, 0, 2)
+
+	itemStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Padding(0, 2)
+
+	selectedItemStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true).
+				Padding(0, 2)
+
+	labelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("141"))
+
+	phaseLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("82")).
+			Bold(true)
+
+	statusStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	helpModalStyle = lipgloss.NewStyle().
+			Width(60).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("236")).
+			Padding(1)
+
+	helpTitleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true).
+			Padding(0, 0, 1, 0)
+
+	helpItemStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252"))
+
+	helpKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205"))
+
+	confirmDialogStyle = lipgloss.NewStyle().
+				Width(50).
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("205")).
+				Foreground(lipgloss.Color("252")).
+				Background(lipgloss.Color("236")).
+				Padding(1)
+
+	confirmDialogTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true).
+				Padding(0, 0, 1, 0)
+
+	confirmDialogOptionStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("252"))
+
+	confirmDialogHighlightStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("205")).
+					Bold(true)
+
+	commandDialogStyle = lipgloss.NewStyle().
+				Width(commandDialogWidth).
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("205")).
+				Foreground(lipgloss.Color("252")).
+				Background(lipgloss.Color("236")).
+				Padding(1)
+
+	commandDialogTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true).
+				Padding(0, 0, 1, 0)
+
+	commandDialogItemStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("252"))
+
+	commandDialogSelectedStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("15")).
+					Background(lipgloss.Color("205")).
+					Bold(true)
+
+	commandDialogHintStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("245"))
+
+	boxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(1, 2)
+
+	mutedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
+	agentBusyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("82")).
+			Bold(true)
+
+	agentWaitingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214")).
+				Bold(true)
+
+	agentBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
+	gitDirtyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203"))
+
+	panePreviewStyle = lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("241")).
+				Foreground(lipgloss.Color("252")).
+				Padding(0, 1)
+
+	panePreviewTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("212")).
+				Bold(true)
+)
+
+func agentStateStyle(state agent.State) lipgloss.Style {
+	switch state {
+	case agent.StateBusy:
+		return agentBusyStyle
+	case agent.StateWaiting:
+		return agentWaitingStyle
+	default:
+		return mutedStyle
+	}
+}
+
+const (
+	specialPathStart    = "start"
+	specialPathStdio    = "--stdio"
+	specialPathWildcard = "**"
+)
+
+var spinners = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+var browserCommands = []string{"xdg-open", "gnome-open", "firefox", "chromium-browser", "google-chrome"}
+
+type model struct {
+	detector          agent.Detector
+	tracker           github.Tracker
+	watcher           *agent.Watcher
+	recorder          *history.Recorder
+	historyEvents     []history.Event
+	historySessions   []history.Session
+	agents            []agent.Agent
+	issues            []issue
+	loading           bool
+	err               error
+	config            config.Config
+	launcher          Launcher
+	spinner           int
+	currentTab        int
+	showHelp          bool
+	helpSearch        string
+	helpMatches       []string
+	width             int
+	height            int
+	ready             bool
+	filterActive      bool
+	selectedIssue     int
+	issueURL          string
+	showConfirmDialog bool
+	// closeWorktree is the worktree of the issue in the done dialog, offered
+	// for removal when the issue is closed
+	closeWorktree     agent.Worktree
+	showCommandDialog bool
+	selectedCommand   int
+	// New Issue Dialog (Issue #27)
+	showNewIssueDialog    bool
+	newIssueRepos         []string
+	newIssueFilteredRepos []string
+	newIssueSelectedRepo  int
+	newIssueDialogMode    string
+	newIssueErrorMessage  string
+	newIssueFilterText    string
+	newIssueTitle         string
+
+	// Issues beyond the first page are loaded as the selection nears the end.
+	// issuesTotal is the search total, which still counts the excluded issues.
+	// issuesPages is how many pages are loaded, all of which refresh refetches
+	issuesTotal       int
+	issuesExcluded    int
+	issuesNextPage    int
+	issuesPages       int
+	loadingMoreIssues bool
+
+	// Phase Dialog (Issue #30)
+	showPhaseDialog bool
+	selectedPhase   int
+	// phaseOptions are the phases the selected issue may move to
+	phaseOptions []config.Phase
+
+	// exitDir receives the exit status of commands that advance a phase or
+	// run as a pipeline step
+	exitDir string
+	// restarting holds the exit status paths of agents being restarted, whose
+	// exit is not the end of their command
+	restarting map[string]bool
+	// exitReads holds the exit status paths being read, so every status is
+	// consumed once; exitsPending holds agents that were gone before their
+	// status was written, which is read again on the next scans
+	exitReads    map[string]bool
+	exitsPending map[string]pendingExit
+
+	// Pipelines; pipelineRuns caches the store keyed by pipeline.Key
+	pipelines          *pipeline.Store
+	pipelineRuns       map[string]pipeline.Run
+	pipelinesChecked   bool
+	showPipelineDialog bool
+	selectedPipeline   int
+
+	// Commands from the command dialog wait here while the agent limits are
+	// reached; selectedJob indexes queue.Jobs() on the Queue tab
+	queue       *queue.Queue
+	selectedJob int
+
+	// Agents tab selection, index into visibleAgents()
+	selectedAgent   int
+	agentSortColumn int
+	agentSortDesc   bool
+
+	// Agents whose changed file list is expanded, keyed by PID
+	expandedAgents map[int]bool
+
+	// Pane preview of the selected agent, captured every previewInterval
+	hidePanePreview   bool
+	panePreview       string
+	panePreviewTarget string
+	panePreviewErr    error
+
+	// Stop Agent Dialog, holds the agent to terminate while open
+	showStopAgentDialog bool
+	stopAgentTarget     agent.Agent
+
+	// Latest agent start or exit seen by the watcher, and its last scan error
+	agentNotice string
+	scanErr     error
+}
+
+const (
+	tabIssues = iota
+	tabAgents
+	tabTimeline
+	tabQueue
+	numTabs = 4
+)
+
+var tabNames = []string{"Issues", "Agents", "Timeline", "Queue"}
+
+var allCommands = []struct {
+	key   string
+	label string
+	desc  string
+}{
+	{"1-4", "tab", "Switch tabs"},
+	{"tab", "next", "Next tab"},
+	{"shift+tab", "prev", "Previous tab"},
+	{"r", "refresh", "Refresh data"},
+	{"a", "active", "Toggle active filter"},
+	{"j", "down", "Next issue (vim)"},
+	{"k", "up", "Previous issue (vim)"},
+	{"o", "open", "Open issue in browser"},
+	{"P", "pipeline", "Run a pipeline of commands on issue"},
+	{"enter", "jump", "Jump to agent's tmux pane (Agents tab)"},
+	{"s", "sort", "Cycle agent sort column (Agents tab)"},
+	{"S", "reverse", "Reverse agent sort order (Agents tab)"},
+	{"w", "waiting", "Cycle agents needing attention"},
+	{"e", "expand", "Show changed files of agent (Agents tab)"},
+	{"v", "preview", "Toggle agent pane preview (Agents tab)"},
+	{"i", "interrupt", "Send Ctrl-C to agent (Agents tab)"},
+	{"x", "stop", "Stop agent with SIGTERM (Agents tab)"},
+	{"R", "restart", "Restart agent with same prompt (Agents tab)"},
+	{"K", "sooner", "Move queued command up (Queue tab)"},
+	{"J", "later", "Move queued command down (Queue tab)"},
+	{"x", "cancel", "Cancel queued command (Queue tab)"},
+	{"enter", "promote", "Start queued command now, ignoring limits (Queue tab)"},
+	{"q", "quit", "Exit application"},
+	{"?", "help", "Show help"},
+	{"esc", "close", "Close help"},
+}
+
+func (m *model) filterHelpCommands() {
+	if m.helpSearch == "" {
+		m.helpMatches = nil
+		for _, cmd := range allCommands {
+			m.helpMatches = append(m.helpMatches, fmt.Sprintf("%-12s %s", cmd.key+":", cmd.desc))
+		}
+		return
+	}
+	searchLower := strings.ToLower(m.helpSearch)
+	m.helpMatches = nil
+	for _, cmd := range allCommands {
+		if strings.Contains(strings.ToLower(cmd.key), searchLower) ||
+			strings.Contains(strings.ToLower(cmd.label), searchLower) ||
+			strings.Contains(strings.ToLower(cmd.desc), searchLower) {
+			m.helpMatches = append(m.helpMatches, fmt.Sprintf("%-12s %s", cmd.key+":", cmd.desc))
+		}
+	}
+	if len(m.helpMatches) == 0 {
+		m.helpMatches = []string{"(no matches)"}
+	}
+}
+
+type issue struct {
+	Number int
+	Title  string
+	State  string
+	Labels []string
+	Repo   string
+}
+
+// groupIssuesByRepo groups issues by repository name (without owner prefix)
+// Issues are sorted by repo name, then by issue number for deterministic display
+func groupIssuesByRepo(issues []issue) map[string][]issue {
+	grouped := make(map[string][]issue)
+	for _, i := range issues {
+		repoName := i.Repo
+		if idx := strings.Index(repoName, "/"); idx > 0 {
+			repoName = repoName[idx+1:]
+		}
+		grouped[repoName] = append(grouped[repoName], i)
+	}
+
+	for repoName := range grouped {
+		sort.SliceStable(grouped[repoName], func(i, j int) bool {
+			return grouped[repoName][i].Number < grouped[repoName][j].Number
+		})
+	}
+
+	return grouped
+}
+
+// sortedRepoKeys extracts and sorts repo names from a grouped map
+func sortedRepoKeys(grouped map[string][]issue) []string {
+	keys := make([]string, 0, len(grouped))
+	for repoName := range grouped {
+		keys = append(keys, repoName)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// groupAgentsByKind groups agents by the name of the kind that matched them
+func groupAgentsByKind(agents []agent.Agent) map[string][]agent.Agent {
+	grouped := make(map[string][]agent.Agent)
+	for _, a := range agents {
+		grouped[a.Name] = append(grouped[a.Name], a)
+	}
+	return grouped
+}
+
+// sortedKindKeys returns agent kind names in alphabetical order
+func sortedKindKeys(grouped map[string][]agent.Agent) []string {
+	keys := make([]string, 0, len(grouped))
+	for kind := range grouped {
+		keys = append(keys, kind)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const (
+	agentSortPID = iota
+	agentSortUptime
+	agentSortCPU
+	agentSortMemory
+	agentSortChildren
+	numAgentSortColumns
+)
+
+var agentSortColumnNames = []string{"PID", "UPTIME", "CPU%", "MEM", "CHILD"}
+
+// sortAgents sorts agents in place by the given column, ties broken by PID
+func sortAgents(agents []agent.Agent, column int, desc bool) {
+	less := func(a, b agent.Agent) bool {
+		switch column {
+		case agentSortUptime:
+			// Older processes have been up longer
+			if !a.StartTime.Equal(b.StartTime) {
+				return a.StartTime.After(b.StartTime)
+			}
+		case agentSortCPU:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent < b.CPUPercent
+			}
+		case agentSortMemory:
+			if a.RSS != b.RSS {
+				return a.RSS < b.RSS
+			}
+		case agentSortChildren:
+			if len(a.SubProcesses) != len(b.SubProcesses) {
+				return len(a.SubProcesses) < len(b.SubProcesses)
+			}
+		}
+		return a.PID < b.PID
+	}
+	sort.SliceStable(agents, func(i, j int) bool {
+		if desc {
+			return less(agents[j], agents[i])
+		}
+		return less(agents[i], agents[j])
+	})
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatUptime renders a duration compactly, e.g. "45s", "12m", "3h04m", "2d05h"
+func formatUptime(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// Options configures a new TUI model.
+type Options struct {
+	// Config selects the listed repos, the agent and where repos are checked
+	// out; settings left empty take the values of config.Default.
+	Config config.Config
+	// Detector finds running agents; nil uses the live /proc detector.
+	Detector agent.Detector
+	// Tracker holds issues, labels and repos; nil uses the GitHub API with
+	// the token from github.Token.
+	Tracker github.Tracker
+	// History records agent lifecycle events for the Timeline tab; nil
+	// disables the history.
+	History *history.Recorder
+	// ScanInterval is how often agents are re-scanned in the background;
+	// zero uses agent.DefaultWatchInterval and a negative value disables it.
+	ScanInterval time.Duration
+	// StateDir holds the exit status of launched agents and the pipeline
+	// state; empty uses history.StateDir.
+	StateDir string
+	// Launcher starts agent commands; nil opens tmux windows.
+	Launcher Launcher
+}
+
+// New returns the root bubbletea model.
+func New(opts Options) tea.Model {
+	detector := opts.Detector
+	if detector == nil {
+		detector = agent.NewProcDetector(agent.DefaultProcRoot)
+	}
+	tracker := opts.Tracker
+	if tracker == nil {
+		// Without a token every request fails with github.ErrNotAuthenticated
+		token, _ := github.Token()
+		tracker = github.NewClient(token)
+	}
+	stateDir := opts.StateDir
+	if stateDir == "" {
+		stateDir = history.StateDir()
+	}
+	launcher := opts.Launcher
+	if launcher == nil {
+		launcher = tmuxLauncher{}
+	}
+	m := &model{
+		detector:     detector,
+		tracker:      tracker,
+		launcher:     launcher,
+		config:       opts.Config.WithDefaults(),
+		recorder:     opts.History,
+		exitDir:      filepath.Join(stateDir, "exit"),
+		restarting:   make(map[string]bool),
+		exitReads:    make(map[string]bool),
+		exitsPending: make(map[string]pendingExit),
+		pipelines:    pipeline.NewStore(filepath.Join(stateDir, "pipelines.json")),
+		pipelineRuns: make(map[string]pipeline.Run),
+		queue:        queue.New(),
+	}
+	if opts.ScanInterval >= 0 {
+		m.watcher = agent.NewWatcher(detector, opts.ScanInterval)
+	}
+	// The history is loaded once; afterwards only new events are added
+	if m.recorder != nil {
+		events, err := m.recorder.Events()
+		if err != nil {
+			m.err = fmt.Errorf("failed to load history: %w", err)
+		}
+		m.addHistory(events)
+	}
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(m.refresh, tick(), previewTick(), m.watchNext(), m.pollPipelinesNext())
+}
+
+// AgentStartedMsg is emitted when the background watcher sees a new agent
+type AgentStartedMsg struct {
+	Agent agent.Agent
+}
+
+// AgentExitedMsg is emitted when the background watcher no longer sees an agent
+type AgentExitedMsg struct {
+	Agent agent.Agent
+}
+
+// historyRecorded carries the events recorded from a watcher snapshot
+type historyRecorded struct {
+	events []history.Event
+	err    error
+}
+
+// agentFinished carries the exit status of an agent linked to an issue; err
+// is set when no status was recorded, i.e. the agent was not launched with
+// exit tracking. poll is set when the status was read while the agent may
+// still be running, so a missing status means nothing.
+type agentFinished struct {
+	agent  agent.Agent
+	status int
+	err    error
+	poll   bool
+}
+
+// pendingExit is an agent that is gone while its exit status is not written
+// yet
+type pendingExit struct {
+	agent agent.Agent
+	since time.Time
+}
+
+// pipelineUpdated reports a change to the pipeline run of an issue; step is
+// set when the current step of the run is to be queued, focus when its window
+// should be shown once it starts, and removed once the run was forgotten
+type pipelineUpdated struct {
+	run     pipeline.Run
+	step    bool
+	focus   bool
+	removed bool
+	err     error
+}
+
+// phaseAdvanced reports the outcome of advancing an issue after its agent
+// exited; a non-zero status leaves the phase unchanged
+type phaseAdvanced struct {
+	repo   string
+	number int
+	phase  string
+	status int
+	labels []string
+	err    error
+}
+
+// watchNext schedules the next background scan. Scans return agent.Snapshot
+// and only detect agents; issues are fetched on refresh.
+func (m *model) watchNext() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	w := m.watcher
+	return func() tea.Msg {
+		return w.Next()
+	}
+}
+
+// applySnapshot updates the agents from a background scan and emits a
+// message for every agent that started or exited
+func (m *model) applySnapshot(snap agent.Snapshot) tea.Cmd {
+	if snap.Err != nil {
+		m.scanErr = snap.Err
+		return m.watchNext()
+	}
+	m.scanErr = nil
+	m.resolveAgentRepos(snap.Agents)
+	m.resolveAgentRepos(snap.Started)
+	m.resolveAgentRepos(snap.Exited)
+	m.agents = snap.Agents
+	m.clampSelectedAgent()
+
+	cmds := []tea.Cmd{m.watchNext(), m.dispatchJobs(0)}
+	for _, p := range m.exitsPending {
+		cmds = append(cmds, m.readExitStatus(p.agent, false))
+	}
+	for _, a := range snap.Started {
+		a := a
+		cmds = append(cmds, func() tea.Msg { return AgentStartedMsg{Agent: a} })
+	}
+	for _, a := range snap.Exited {
+		a := a
+		cmds = append(cmds, func() tea.Msg { return AgentExitedMsg{Agent: a} })
+	}
+	// Steps that exited unseen, between scans, are only found by their status
+	cmds = append(cmds, m.reconcilePipelines(false, snap.Exited))
+	if m.recorder != nil {
+		agents := snap.Agents
+		recorder := m.recorder
+		cmds = append(cmds, func() tea.Msg {
+			events, err := recorder.Observe(agents)
+			if err != nil {
+				err = fmt.Errorf("failed to record history: %w", err)
+			}
+			return historyRecorded{events: events, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// describeAgent names an agent in notices, e.g. "OpenCode #42 /tdd (PID 1001)"
+func describeAgent(a agent.Agent) string {
+	name := a.Name
+	if a.Issue > 0 {
+		name += fmt.Sprintf(" #%d", a.Issue)
+	}
+	if a.Command != "" {
+		name += " " + a.Command
+	}
+	return fmt.Sprintf("%s (PID %d)", name, a.PID)
+}
+
+// previewTickMsg triggers a capture of the selected agent's pane
+type previewTickMsg struct{}
+
+func previewTick() tea.Cmd {
+	return tea.Tick(previewInterval, func(time.Time) tea.Msg {
+		return previewTickMsg{}
+	})
+}
+
+// panePreviewMsg carries captured pane text back to Update
+type panePreviewMsg struct {
+	target string
+	text   string
+	err    error
+}
+
+// capturePreview captures the selected agent's pane when the preview is visible
+func (m *model) capturePreview() tea.Cmd {
+	if m.currentTab != tabAgents || m.hidePanePreview {
+		return nil
+	}
+	a, ok := m.selectedAgentEntry()
+	if !ok || !a.InPane() {
+		return nil
+	}
+	pane := a.Pane
+	return func() tea.Msg {
+		text, err := agent.CapturePane(pane, previewMaxLines)
+		return panePreviewMsg{target: pane.Target(), text: text, err: err}
+	}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
+		return t
+	})
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Handle issue-input mode for all keys not explicitly handled
+	if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "enter", "return", "escape", "esc", "n", "y", "q", "ctrl+c",
+				"j", "k", "r", "a", "o", "p", "d", "?", "tab", "shift+tab",
+				"up", "down", "backspace":
+				// Let these be handled by their specific cases below
+			default:
+				// For all other keys (including å, ö, ä), add to input
+				if len(keyMsg.String()) > 0 {
+					m.newIssueTitle += keyMsg.String()
+					return m, nil
+				}
+			}
+		}
+	}
+
+	switch msg := msg.(type) {
+	case time.Time:
+		m.spinner = (m.spinner + 1) % len(spinners)
+		return m, tick()
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "q"
+				return m, nil
+			}
+			return m, tea.Quit
+		case "r":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "r"
+				return m, nil
+			}
+			m.loading = true
+			return m, m.refresh
+		case "a":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "a"
+				return m, nil
+			}
+			m.filterActive = !m.filterActive
+			m.clampSelectedAgent()
+			return m, nil
+		case "s":
+			if m.overlayOpen() {
+				break
+			}
+			if m.currentTab == tabAgents {
+				m.agentSortColumn = (m.agentSortColumn + 1) % numAgentSortColumns
+			}
+			return m, nil
+		case "S":
+			if m.overlayOpen() {
+				break
+			}
+			if m.currentTab == tabAgents {
+				m.agentSortDesc = !m.agentSortDesc
+			}
+			return m, nil
+		case "v":
+			if m.overlayOpen() {
+				break
+			}
+			if m.currentTab == tabAgents {
+				m.hidePanePreview = !m.hidePanePreview
+				return m, m.capturePreview()
+			}
+			return m, nil
+		case "e":
+			if m.overlayOpen() {
+				break
+			}
+			m.toggleSelectedAgentExpanded()
+			return m, nil
+		case "w":
+			if m.showConfirmDialog {
+				if m.closeWorktree.Dir == "" {
+					return m, nil
+				}
+				return m, m.confirmAndCloseIssue(true)
+			}
+			if m.overlayOpen() {
+				break
+			}
+			m.selectNextAgentNeedingAttention()
+			return m, m.capturePreview()
+		case "i":
+			if m.overlayOpen() {
+				break
+			}
+			m.interruptSelectedAgent()
+			return m, nil
+		case "x":
+			if m.overlayOpen() {
+				break
+			}
+			m.openStopAgentDialog()
+			return m, m.cancelSelectedJob()
+		case "R":
+			if m.overlayOpen() {
+				break
+			}
+			return m, m.restartSelectedAgent()
+		case "K":
+			if m.overlayOpen() {
+				break
+			}
+			m.moveSelectedJob(-1)
+			return m, nil
+		case "J":
+			if m.overlayOpen() {
+				break
+			}
+			m.moveSelectedJob(1)
+			return m, nil
+		case "?":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "?"
+				return m, nil
+			}
+			m.showHelp = true
+			m.helpSearch = ""
+			m.filterHelpCommands()
+			return m, nil
+		case "escape", "esc":
+			if m.showHelp {
+				m.showHelp = false
+				m.helpSearch = ""
+			}
+			if m.showConfirmDialog {
+				m.showConfirmDialog = false
+			}
+			if m.showStopAgentDialog {
+				m.closeStopAgentDialog()
+			}
+			if m.showCommandDialog {
+				m.showCommandDialog = false
+				m.selectedCommand = -1
+			}
+			if m.showNewIssueDialog {
+				m.showNewIssueDialog = false
+				m.newIssueDialogMode = ""
+				m.newIssueFilterText = ""
+				m.newIssueTitle = ""
+				m.newIssueSelectedRepo = 0
+			}
+			if m.showPhaseDialog {
+				m.showPhaseDialog = false
+				m.selectedPhase = -1
+			}
+			if m.showPipelineDialog {
+				m.closePipelineDialog()
+			}
+			return m, nil
+		case "tab":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "\t"
+				return m, nil
+			}
+			m.currentTab = (m.currentTab + 1) % numTabs
+			return m, nil
+		case "shift+tab":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "\t"
+				return m, nil
+			}
+			m.currentTab = (m.currentTab - 1 + numTabs) % numTabs
+			return m, nil
+		case "j":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "j"
+				return m, nil
+			}
+			m.moveToNextIssue()
+			m.moveToNextAgent()
+			m.moveToNextJob()
+			return m, tea.Batch(m.capturePreview(), m.loadMoreIssues())
+		case "k":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "k"
+				return m, nil
+			}
+			m.moveToPreviousIssue()
+			m.moveToPreviousAgent()
+			m.moveToPreviousJob()
+			return m, m.capturePreview()
+		case "o":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "o"
+				return m, nil
+			}
+			return m, m.openSelectedIssueInBrowser()
+		case "p":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "p"
+				return m, nil
+			}
+			if m.currentTab == tabIssues && len(m.issues) > 0 && m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+				m.openPhaseDialog()
+			}
+			return m, nil
+		case "P":
+			if m.overlayOpen() {
+				break
+			}
+			m.openPipelineDialog()
+			return m, nil
+		case "d":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "d"
+				return m, nil
+			}
+			m.showCloseIssueDialog()
+			return m, nil
+		case "y":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "y"
+				return m, nil
+			}
+			if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" {
+				m.executeNewIssueSelection()
+				return m, nil
+			}
+			if m.showCommandDialog {
+				return m, m.executeSelectedCommand()
+			}
+			if m.showPipelineDialog {
+				return m, m.executePipelineSelection()
+			}
+			if m.showStopAgentDialog {
+				return m, m.confirmStopAgent()
+			}
+			return m, m.confirmAndCloseIssue(false)
+		case "enter":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.executeIssueTitleInput()
+				return m, nil
+			}
+			if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" {
+				m.executeNewIssueSelection()
+				return m, nil
+			}
+			if m.showConfirmDialog {
+				return m, m.confirmAndCloseIssue(false)
+			}
+			if m.showStopAgentDialog {
+				return m, m.confirmStopAgent()
+			}
+			if m.showPhaseDialog {
+				m.executePhaseSelection()
+				return m, nil
+			}
+			if m.showPipelineDialog {
+				return m, m.executePipelineSelection()
+			}
+			if m.showCommandDialog {
+				return m, m.executeSelectedCommand()
+			}
+			if m.currentTab == tabIssues && len(m.issues) > 0 && m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+				m.showCommandDialog = true
+				m.selectedCommand = 0
+				return m, nil
+			}
+			if m.currentTab == tabAgents {
+				m.jumpToSelectedAgent()
+				return m, nil
+			}
+			if m.currentTab == tabQueue {
+				return m, m.promoteSelectedJob()
+			}
+		case "n":
+			if m.showCommandDialog {
+				m.showCommandDialog = false
+				m.selectedCommand = -1
+				return m, nil
+			}
+			if m.showPipelineDialog {
+				m.closePipelineDialog()
+				return m, nil
+			}
+			if m.showStopAgentDialog {
+				m.closeStopAgentDialog()
+				return m, nil
+			}
+			if m.showNewIssueDialog {
+				if m.newIssueDialogMode == "issue-input" {
+					m.newIssueTitle += "n"
+					return m, nil
+				}
+				m.showNewIssueDialog = false
+				m.newIssueFilterText = ""
+				m.newIssueTitle = ""
+				return m, nil
+			}
+			if m.currentTab == tabIssues {
+				m.openNewIssueDialog()
+			}
+			m.showConfirmDialog = false
+			return m, nil
+		case "up":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "↑"
+				return m, nil
+			}
+			if m.showCommandDialog && m.selectedCommand > 0 {
+				m.selectedCommand--
+			}
+			if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" && m.newIssueSelectedRepo > 0 {
+				m.newIssueSelectedRepo--
+			}
+			if m.showPhaseDialog && m.selectedPhase > 0 {
+				m.selectedPhase--
+			}
+			if m.showPipelineDialog && m.selectedPipeline > 0 {
+				m.selectedPipeline--
+			}
+			return m, nil
+		case "down":
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += "↓"
+				return m, nil
+			}
+			if m.showCommandDialog && m.selectedCommand < len(m.config.Commands)-1 {
+				m.selectedCommand++
+			}
+			if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" && m.newIssueSelectedRepo < len(m.newIssueFilteredRepos)-1 {
+				m.newIssueSelectedRepo++
+			}
+			if m.showPhaseDialog && m.selectedPhase < len(m.phaseOptions)-1 {
+				m.selectedPhase++
+			}
+			if m.showPipelineDialog && m.selectedPipeline < len(m.config.Pipelines)-1 {
+				m.selectedPipeline++
+			}
+			return m, nil
+		}
+		if m.showHelp {
+			if msg.String() == "backspace" {
+				if len(m.helpSearch) > 0 {
+					m.helpSearch = m.helpSearch[:len(m.helpSearch)-1]
+					m.filterHelpCommands()
+				}
+			} else if len(msg.String()) == 1 {
+				m.helpSearch += msg.String()
+				m.filterHelpCommands()
+			}
+			return m, nil
+		}
+		if m.showCommandDialog && len(msg.String()) == 1 {
+			key := msg.String()
+			if n := int(key[0] - '1'); key >= "1" && key <= "9" && n < len(m.config.Commands) {
+				m.selectedCommand = n
+				return m, m.executeSelectedCommand()
+			}
+		}
+		// Handle Enter key for new issue dialog BEFORE the single-char check
+		// This fixes the bug where Enter was never handled because "enter" has len=5
+		if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" && (msg.String() == "enter" || msg.String() == "return") {
+			m.executeNewIssueSelection()
+			return m, nil
+		}
+		if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" && len(msg.String()) == 1 {
+			key := msg.String()
+			if key >= "1" && key <= "9" {
+				repoNum := int(key[0] - '1')
+				if repoNum < len(m.newIssueFilteredRepos) {
+					m.newIssueSelectedRepo = repoNum
+					m.executeNewIssueSelection()
+					return m, nil
+				}
+			}
+			m.newIssueFilterText += key
+			m.filterNewIssueRepos()
+			return m, nil
+		}
+		if m.showNewIssueDialog && m.newIssueDialogMode == "repo-select" && msg.String() == "backspace" {
+			if len(m.newIssueFilterText) > 0 {
+				m.newIssueFilterText = m.newIssueFilterText[:len(m.newIssueFilterText)-1]
+				m.filterNewIssueRepos()
+			}
+			return m, nil
+		}
+		// Handle issue title input mode
+		if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+			if msg.String() == "enter" || msg.String() == "return" {
+				m.executeIssueTitleInput()
+				return m, nil
+			}
+			if msg.String() == "escape" || msg.String() == "esc" || msg.String() == "n" {
+				m.newIssueDialogMode = "repo-select"
+				m.newIssueTitle = ""
+				return m, nil
+			}
+			if msg.String() == "backspace" {
+				if len(m.newIssueTitle) > 0 {
+					m.newIssueTitle = m.newIssueTitle[:len(m.newIssueTitle)-1]
+				}
+				return m, nil
+			}
+			if len(msg.String()) == 1 {
+				m.newIssueTitle += msg.String()
+				return m, nil
+			}
+			return m, nil
+		}
+		if m.showPipelineDialog {
+			key := msg.String()
+			if n := int(key[0] - '1'); len(key) == 1 && key >= "1" && key <= "9" && n < len(m.config.Pipelines) {
+				m.selectedPipeline = n
+				return m, m.executePipelineSelection()
+			}
+			return m, nil
+		}
+		if m.showPhaseDialog {
+			if msg.String() == "enter" || msg.String() == "return" {
+				m.executePhaseSelection()
+				return m, nil
+			}
+			if len(msg.String()) == 1 {
+				key := msg.String()
+				if n := int(key[0] - '1'); key >= "1" && key <= "9" && n < len(m.phaseOptions) {
+					m.selectedPhase = n
+					m.executePhaseSelection()
+					return m, nil
+				}
+			}
+			if msg.String() == "n" {
+				m.showPhaseDialog = false
+				m.selectedPhase = -1
+				return m, nil
+			}
+			return m, nil
+		}
+		if len(msg.String()) >= 1 {
+			key := msg.String()
+			if m.showNewIssueDialog && m.newIssueDialogMode == "issue-input" {
+				m.newIssueTitle += key
+				return m, nil
+			}
+			if key >= "1" && key <= "9" {
+				tabNum := int(key[0] - '0')
+				if tabNum >= 1 && tabNum <= numTabs {
+					m.currentTab = tabNum - 1
+				}
+			}
+		}
+	case pipelinePollMsg:
+		return m, tea.Batch(m.reconcilePipelines(false, nil), m.pollPipelinesNext())
+	case previewTickMsg:
+		return m, tea.Batch(m.capturePreview(), previewTick())
+	case panePreviewMsg:
+		if msg.err != nil {
+			m.panePreview = ""
+			m.panePreviewErr = msg.err
+		} else {
+			m.panePreview = msg.text
+			m.panePreviewErr = nil
+		}
+		m.panePreviewTarget = msg.target
+		return m, nil
+	case agentActionFailed:
+		m.loading = false
+		m.err = msg.err
+	case moreIssuesLoaded:
+		m.loadingMoreIssues = false
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to load more issues: %w", msg.err)
+			return m, nil
+		}
+		m.appendIssues(msg.page)
+	case agent.Snapshot:
+		return m, m.applySnapshot(msg)
+	case AgentStartedMsg:
+		m.agentNotice = "▶ " + describeAgent(msg.Agent) + " started"
+	case AgentExitedMsg:
+		m.agentNotice = "■ " + describeAgent(msg.Agent) + " exited"
+		return m, m.readExitStatus(msg.Agent, false)
+	case agentFinished:
+		a := msg.agent
+		path := agent.ExitStatusPath(m.exitDir, a.Repo, a.Issue, a.Command)
+		delete(m.exitReads, path)
+		if m.restarting[path] {
+			delete(m.restarting, path)
+			return m, nil
+		}
+		if errors.Is(msg.err, os.ErrNotExist) && (msg.poll || m.awaitExitStatus(path, a)) {
+			return m, nil
+		}
+		delete(m.exitsPending, path)
+		return m, tea.Batch(m.continuePipeline(msg), m.advanceAfterExit(msg))
+	case jobStarted:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		if i := m.findIssue(msg.job.Repo, msg.job.Issue); i >= 0 && msg.labels != nil {
+			m.issues[i].Labels = msg.labels
+		}
+		m.clampSelectedJob()
+	case pipelineUpdated:
+		if msg.err != nil {
+			m.err = fmt.Errorf("pipeline for issue #%d: %w", msg.run.Issue, msg.err)
+		}
+		if msg.run.Repo == "" {
+			return m, nil
+		}
+		if msg.removed {
+			delete(m.pipelineRuns, msg.run.Key())
+		} else {
+			m.pipelineRuns[msg.run.Key()] = msg.run
+		}
+		m.agentNotice = describeRun(msg.run)
+		m.clampSelectedJob()
+		if msg.step && msg.run.Status == pipeline.StatusRunning {
+			return m, m.queuePipelineStep(msg.run, msg.focus)
+		}
+	case phaseAdvanced:
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to advance issue #%d: %w", msg.number, msg.err)
+		}
+		if agent.Stopped(msg.status) {
+			m.agentNotice = fmt.Sprintf("■ issue #%d: agent stopped, phase unchanged", msg.number)
+			return m, nil
+		}
+		if msg.status != 0 {
+			m.agentNotice = fmt.Sprintf("■ issue #%d: agent exited with status %d, phase unchanged", msg.number, msg.status)
+			return m, nil
+		}
+		if i := m.findIssue(msg.repo, msg.number); i >= 0 && msg.labels != nil {
+			m.issues[i].Labels = msg.labels
+		}
+		if msg.err == nil {
+			m.agentNotice = fmt.Sprintf("■ issue #%d moved to %s", msg.number, msg.phase)
+		}
+	case historyRecorded:
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.addHistory(msg.events)
+		}
+	case refreshComplete:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		m.issues = msg.issues.issues
+		m.resolveAgentRepos(msg.agents)
+		m.agents = msg.agents
+		m.issuesTotal = msg.issues.total
+		m.issuesExcluded = msg.issues.excluded
+		m.issuesNextPage = msg.issues.nextPage
+		m.issuesPages = msg.issues.pages
+		m.clampSelectedIssue()
+		m.addHistory(msg.history)
+		m.clampSelectedAgent()
+		var cmds []tea.Cmd
+		if msg.detected {
+			cmds = append(cmds, m.dispatchJobs(0))
+		}
+		if msg.runs != nil {
+			m.pipelineRuns = make(map[string]pipeline.Run, len(msg.runs))
+			for _, run := range msg.runs {
+				m.pipelineRuns[run.Key()] = run
+			}
+			if msg.detected {
+				cmds = append(cmds, m.reconcilePipelines(!m.pipelinesChecked, nil))
+				m.pipelinesChecked = true
+			}
+		}
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
+
+func (m *model) moveToNextIssue() {
+	if m.currentTab == tabIssues && len(m.issues) > 0 {
+		grouped := groupIssuesByRepo(m.issues)
+		repoNames := sortedRepoKeys(grouped)
+		visualOrder := buildVisualOrder(grouped, repoNames)
+
+		currentIdx := -1
+		for i, iss := range visualOrder {
+			if iss.Number == m.issues[m.selectedIssue].Number && iss.Repo == m.issues[m.selectedIssue].Repo {
+				currentIdx = i
+				break
+			}
+		}
+
+		if currentIdx >= 0 && currentIdx < len(visualOrder)-1 {
+			nextIssue := visualOrder[currentIdx+1]
+			for i, iss := range m.issues {
+				if iss.Number == nextIssue.Number && iss.Repo == nextIssue.Repo {
+					m.selectedIssue = i
+					break
+				}
+			}
+		}
+	}
+}
+
+func (m *model) moveToPreviousIssue() {
+	if m.currentTab == tabIssues && len(m.issues) > 0 {
+		grouped := groupIssuesByRepo(m.issues)
+		repoNames := sortedRepoKeys(grouped)
+		visualOrder := buildVisualOrder(grouped, repoNames)
+
+		currentIdx := -1
+		for i, iss := range visualOrder {
+			if iss.Number == m.issues[m.selectedIssue].Number && iss.Repo == m.issues[m.selectedIssue].Repo {
+				currentIdx = i
+				break
+			}
+		}
+
+		if currentIdx > 0 {
+			prevIssue := visualOrder[currentIdx-1]
+			for i, iss := range m.issues {
+				if iss.Number == prevIssue.Number && iss.Repo == prevIssue.Repo {
+					m.selectedIssue = i
+					break
+				}
+			}
+		}
+	}
+}
+
+// clampSelectedIssue keeps the selection inside the issue list after it shrinks
+func (m *model) clampSelectedIssue() {
+	if m.selectedIssue >= len(m.issues) {
+		m.selectedIssue = len(m.issues) - 1
+	}
+	if m.selectedIssue < 0 {
+		m.selectedIssue = 0
+	}
+}
+
+// moreIssuesLoaded carries the next page of issues back to Update
+type moreIssuesLoaded struct {
+	page issuePage
+	err  error
+}
+
+// loadMoreIssues fetches the next page once the selection is within
+// loadMoreThreshold issues of the end of the loaded list
+func (m *model) loadMoreIssues() tea.Cmd {
+	if m.currentTab != tabIssues || m.issuesNextPage == 0 || m.loadingMoreIssues ||
+		m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		return nil
+	}
+	grouped := groupIssuesByRepo(m.issues)
+	visualOrder := buildVisualOrder(grouped, sortedRepoKeys(grouped))
+	selected := m.issues[m.selectedIssue]
+	for i, iss := range visualOrder {
+		if iss.Number == selected.Number && iss.Repo == selected.Repo {
+			if i < len(visualOrder)-loadMoreThreshold {
+				return nil
+			}
+			break
+		}
+	}
+
+	m.loadingMoreIssues = true
+	tracker, sources, page := m.tracker, m.config.GitHub, m.issuesNextPage
+	return func() tea.Msg {
+		result, err := fetchIssuePage(tracker, sources, page)
+		return moreIssuesLoaded{page: result, err: err}
+	}
+}
+
+// appendIssues adds a page of issues, skipping issues already loaded since
+// results can shift between pages while issues are opened and closed
+func (m *model) appendIssues(page issuePage) {
+	m.issues = appendNewIssues(m.issues, page.issues)
+	m.issuesTotal = page.total
+	m.issuesExcluded += page.excluded
+	m.issuesNextPage = page.nextPage
+	m.issuesPages++
+}
+
+// appendNewIssues appends the issues of more that are not in issues yet
+func appendNewIssues(issues, more []issue) []issue {
+	loaded := make(map[string]bool, len(issues))
+	for _, i := range issues {
+		loaded[fmt.Sprintf("%s#%d", i.Repo, i.Number)] = true
+	}
+	for _, i := range more {
+		if !loaded[fmt.Sprintf("%s#%d", i.Repo, i.Number)] {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+func buildVisualOrder(grouped map[string][]issue, repoNames []string) []issue {
+	var visualOrder []issue
+	for _, repoName := range repoNames {
+		visualOrder = append(visualOrder, grouped[repoName]...)
+	}
+	return visualOrder
+}
+
+// agentForIssue returns the running agent linked to an issue, if any
+func (m *model) agentForIssue(i issue) (agent.Agent, bool) {
+	for _, a := range m.agents {
+		if a.Issue != i.Number {
+			continue
+		}
+		if a.Repo != "" && strings.EqualFold(a.Repo, i.Repo) {
+			return a, true
+		}
+		if a.Repo == "" && a.WorkingDir != "" && strings.EqualFold(getRepoName(a.WorkingDir), getRepoName(i.Repo)) {
+			return a, true
+		}
+	}
+	return agent.Agent{}, false
+}
+
+// visibleAgents returns the agents shown in the Agents tab, in display order
+func (m *model) visibleAgents() []agent.Agent {
+	agentsToShow := m.agents
+	if m.filterActive {
+		agentsToShow = agent.FilterActive(m.agents, true)
+	}
+	grouped := m.agentGroups(agentsToShow)
+	var visualOrder []agent.Agent
+	for _, kind := range sortedKindKeys(grouped) {
+		visualOrder = append(visualOrder, grouped[kind]...)
+	}
+	return visualOrder
+}
+
+// agentGroups groups agents by kind and sorts each group by the selected column
+func (m *model) agentGroups(agents []agent.Agent) map[string][]agent.Agent {
+	grouped := groupAgentsByKind(agents)
+	for kind := range grouped {
+		sortAgents(grouped[kind], m.agentSortColumn, m.agentSortDesc)
+	}
+	return grouped
+}
+
+func (m *model) selectedAgentEntry() (agent.Agent, bool) {
+	visible := m.visibleAgents()
+	if m.selectedAgent < 0 || m.selectedAgent >= len(visible) {
+		return agent.Agent{}, false
+	}
+	return visible[m.selectedAgent], true
+}
+
+func (m *model) moveToNextAgent() {
+	if m.currentTab == tabAgents && m.selectedAgent < len(m.visibleAgents())-1 {
+		m.selectedAgent++
+	}
+}
+
+func (m *model) moveToPreviousAgent() {
+	if m.currentTab == tabAgents && m.selectedAgent > 0 {
+		m.selectedAgent--
+	}
+}
+
+// selectNextAgentNeedingAttention switches to the Agents tab and moves the
+// selection to the next agent waiting for input, wrapping around
+func (m *model) selectNextAgentNeedingAttention() {
+	if agent.CountNeedingAttention(m.agents) == 0 {
+		return
+	}
+	m.currentTab = tabAgents
+	if agent.CountNeedingAttention(m.visibleAgents()) == 0 {
+		m.filterActive = false
+	}
+
+	visible := m.visibleAgents()
+	for offset := 1; offset <= len(visible); offset++ {
+		idx := (m.selectedAgent + offset) % len(visible)
+		if visible[idx].NeedsAttention() {
+			m.selectedAgent = idx
+			return
+		}
+	}
+}
+
+// clampSelectedAgent keeps the agent selection inside the visible list after
+// a refresh or filter change
+func (m *model) clampSelectedAgent() {
+	if n := len(m.visibleAgents()); m.selectedAgent >= n {
+		m.selectedAgent = n - 1
+	}
+	if m.selectedAgent < 0 {
+		m.selectedAgent = 0
+	}
+}
+
+// jumpToSelectedAgent switches the tmux client to the pane of the selected agent
+func (m *model) jumpToSelectedAgent() {
+	a, ok := m.selectedAgentEntry()
+	if !ok {
+		return
+	}
+	if !a.InPane() {
+		m.err = fmt.Errorf("agent %d is not running in a tmux pane", a.PID)
+		return
+	}
+
+	target := a.Pane.Target()
+	if err := exec.Command("tmux", "select-window", "-t", target).Run(); err != nil {
+		m.err = fmt.Errorf("failed to select tmux window: %w", err)
+		return
+	}
+	if err := exec.Command("tmux", "select-pane", "-t", target).Run(); err != nil {
+		m.err = fmt.Errorf("failed to select tmux pane: %w", err)
+		return
+	}
+	if err := exec.Command("tmux", "switch-client", "-t", a.Pane.Session).Run(); err != nil {
+		m.err = fmt.Errorf("failed to switch tmux client: %w", err)
+	}
+}
+
+// agentActionFailed reports an agent action that failed after Update returned
+type agentActionFailed struct {
+	err error
+}
+
+// interruptSelectedAgent sends Ctrl-C to the selected agent
+func (m *model) interruptSelectedAgent() {
+	if m.currentTab != tabAgents {
+		return
+	}
+	a, ok := m.selectedAgentEntry()
+	if !ok {
+		return
+	}
+	if err := agent.Interrupt(a); err != nil {
+		m.err = fmt.Errorf("failed to interrupt agent: %w", err)
+	}
+}
+
+func (m *model) openStopAgentDialog() {
+	if m.currentTab != tabAgents {
+		return
+	}
+	if a, ok := m.selectedAgentEntry(); ok {
+		m.showStopAgentDialog = true
+		m.stopAgentTarget = a
+	}
+}
+
+func (m *model) closeStopAgentDialog() {
+	m.showStopAgentDialog = false
+	m.stopAgentTarget = agent.Agent{}
+}
+
+// confirmStopAgent sends SIGTERM to the agent chosen in the stop dialog and
+// refreshes once it has had a moment to exit
+func (m *model) confirmStopAgent() tea.Cmd {
+	if !m.showStopAgentDialog {
+		return nil
+	}
+	target := m.stopAgentTarget
+	m.closeStopAgentDialog()
+
+	if err := agent.Terminate(target); err != nil {
+		m.err = fmt.Errorf("failed to stop agent: %w", err)
+		return nil
+	}
+	m.loading = true
+	return func() tea.Msg {
+		time.Sleep(500 * time.Millisecond)
+		return m.refresh()
+	}
+}
+
+// restartSelectedAgent restarts the selected agent with its original prompt.
+// Agents linked to an issue write their exit status again, and the exit of
+// the old process does not count as the end of their command.
+func (m *model) restartSelectedAgent() tea.Cmd {
+	if m.currentTab != tabAgents {
+		return nil
+	}
+	a, ok := m.selectedAgentEntry()
+	if !ok {
+		return nil
+	}
+	statusPath := ""
+	if a.Repo != "" && a.Issue != 0 && a.Command != "" && a.InPane() && len(a.Cmdline) > 0 {
+		statusPath = agent.ExitStatusPath(m.exitDir, a.Repo, a.Issue, a.Command)
+		if err := os.MkdirAll(m.exitDir, 0o755); err != nil {
+			m.err = fmt.Errorf("failed to create exit status dir: %w", err)
+			return nil
+		}
+		m.restarting[statusPath] = true
+	}
+	m.loading = true
+	return func() tea.Msg {
+		if err := agent.Restart(a, statusPath); err != nil {
+			return agentActionFailed{err: fmt.Errorf("failed to restart agent: %w", err)}
+		}
+		time.Sleep(500 * time.Millisecond)
+		return m.refresh()
+	}
+}
+
+func (m *model) openPhaseDialog() {
+	if m.currentTab != tabIssues || len(m.issues) == 0 || m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		return
+	}
+	issue := m.issues[m.selectedIssue]
+	workflow := m.config.Workflow(issue.Repo)
+	options := workflow.Transitions(issue.Labels)
+	if len(options) == 0 {
+		current, _ := workflow.Current(issue.Labels)
+		m.err = fmt.Errorf("issue #%d is in the final phase %q", issue.Number, current.Label)
+		return
+	}
+	m.phaseOptions = options
+	m.showPhaseDialog = true
+	m.selectedPhase = 0
+}
+
+func (m *model) executePhaseSelection() {
+	if !m.showPhaseDialog || m.selectedPhase < 0 || m.selectedPhase >= len(m.phaseOptions) {
+		m.showPhaseDialog = false
+		m.selectedPhase = -1
+		return
+	}
+
+	if m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		m.showPhaseDialog = false
+		m.selectedPhase = -1
+		return
+	}
+
+	phase := m.phaseOptions[m.selectedPhase]
+	issue := &m.issues[m.selectedIssue]
+	labels, err := setPhase(m.tracker, m.config.Workflow(issue.Repo), issue.Repo, issue.Number, issue.Labels, phase)
+	issue.Labels = labels
+	if err != nil {
+		m.err = err
+	}
+
+	m.showPhaseDialog = false
+	m.selectedPhase = -1
+}
+
+// =============================================================================
+// Pipelines
+// =============================================================================
+
+func (m *model) openPipelineDialog() {
+	if m.currentTab != tabIssues || len(m.issues) == 0 || m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		return
+	}
+	if len(m.config.Pipelines) == 0 {
+		m.err = fmt.Errorf("no pipelines configured")
+		return
+	}
+	m.showPipelineDialog = true
+	m.selectedPipeline = 0
+}
+
+func (m *model) closePipelineDialog() {
+	m.showPipelineDialog = false
+	m.selectedPipeline = -1
+}
+
+// executePipelineSelection starts the selected pipeline on the selected issue,
+// or resumes it at the step that failed
+func (m *model) executePipelineSelection() tea.Cmd {
+	if !m.showPipelineDialog || m.selectedPipeline < 0 || m.selectedPipeline >= len(m.config.Pipelines) ||
+		m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		m.closePipelineDialog()
+		return nil
+	}
+	p := m.config.Pipelines[m.selectedPipeline]
+	iss := m.issues[m.selectedIssue]
+	m.closePipelineDialog()
+
+	store := m.pipelines
+	return func() tea.Msg {
+		run, err := store.Start(iss.Repo, iss.Number, p.Name, p.Steps)
+		return pipelineUpdated{run: run, step: err == nil, focus: true, err: err}
+	}
+}
+
+// continuePipeline launches the next step of the issue's pipeline when the
+// agent of its current step exited successfully, and stops the pipeline when
+// it did not.
+func (m *model) continuePipeline(f agentFinished) tea.Cmd {
+	a := f.agent
+	run, ok := m.pipelineRuns[pipeline.Key(a.Repo, a.Issue)]
+	if !ok || run.Status != pipeline.StatusRunning || run.Command() != a.Command {
+		return nil
+	}
+	store := m.pipelines
+	return func() tea.Msg {
+		if f.err != nil || f.status != 0 {
+			reason := fmt.Sprintf("%s exited with status %d", a.Command, f.status)
+			if f.err != nil {
+				reason = fmt.Sprintf("%s exited without a recorded status", a.Command)
+			} else if agent.Stopped(f.status) {
+				reason = fmt.Sprintf("%s was stopped", a.Command)
+			}
+			run, err := store.Fail(a.Repo, a.Issue, reason)
+			return pipelineUpdated{run: run, err: err}
+		}
+		run, err := store.Advance(a.Repo, a.Issue)
+		if err == nil && run.Status == pipeline.StatusDone {
+			// A finished run has nothing left to show or resume
+			return pipelineUpdated{run: run, removed: true, err: store.Remove(a.Repo, a.Issue)}
+		}
+		return pipelineUpdated{run: run, step: err == nil, err: err}
+	}
+}
+
+// queuePipelineStep queues the current step of run like any other command,
+// so steps wait for a free slot under the agent limits
+func (m *model) queuePipelineStep(run pipeline.Run, focus bool) tea.Cmd {
+	title := ""
+	if i := m.findIssue(run.Repo, run.Issue); i >= 0 {
+		title = m.issues[i].Title
+	}
+	job := m.queue.PushStep(run.Repo, run.Issue, title, run.Command())
+	focusID := 0
+	if focus {
+		focusID = job.ID
+	}
+	cmd := m.dispatchJobs(focusID)
+	if n := m.queuePosition(job.ID); n > 0 {
+		m.agentNotice = fmt.Sprintf("⏳ issue #%d: %s queued at position %d", run.Issue, job.Command, n)
+	}
+	return cmd
+}
+
+// failPipeline stops the pipeline of an issue because its step could not run
+func (m *model) failPipeline(repo string, issue int, err error) tea.Msg {
+	failed, storeErr := m.pipelines.Fail(repo, issue, err.Error())
+	if storeErr != nil {
+		return pipelineUpdated{run: failed, err: storeErr}
+	}
+	return pipelineUpdated{run: failed, err: err}
+}
+
+// reconcilePipelines reads the exit status of the current step of every
+// running pipeline, so a step that exits between scans, or before the first
+// one, still moves its pipeline on. On startup a step whose agent is not
+// running exited while the monitor was not running, so a missing status
+// stops its pipeline. Steps whose agent is among exited are left to
+// AgentExitedMsg, which waits for their status.
+func (m *model) reconcilePipelines(startup bool, exited []agent.Agent) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, run := range m.pipelineRuns {
+		if run.Status != pipeline.StatusRunning || runsStep(exited, run) {
+			continue
+		}
+		a := agent.Agent{Repo: run.Repo, Issue: run.Issue, Command: run.Command()}
+		cmds = append(cmds, m.readExitStatus(a, !startup || m.pipelineAgentRunning(run)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// pipelinePollMsg triggers reconcilePipelines when agents are not scanned in
+// the background
+type pipelinePollMsg struct{}
+
+func (m *model) pollPipelinesNext() tea.Cmd {
+	if m.watcher != nil {
+		return nil
+	}
+	return tea.Tick(agent.DefaultWatchInterval, func(time.Time) tea.Msg {
+		return pipelinePollMsg{}
+	})
+}
+
+func (m *model) pipelineAgentRunning(run pipeline.Run) bool {
+	return runsStep(m.agents, run)
+}
+
+// runsStep reports whether one of agents runs the current step of run
+func runsStep(agents []agent.Agent, run pipeline.Run) bool {
+	for _, a := range agents {
+		if a.Repo == run.Repo && a.Issue == run.Issue && a.Command == run.Command() {
+			return true
+		}
+	}
+	return false
+}
+
+// describeRun summarizes a pipeline run for the notice line
+func describeRun(run pipeline.Run) string {
+	switch run.Status {
+	case pipeline.StatusDone:
+		return fmt.Sprintf("⛓ issue #%d: %s done", run.Issue, run.Name)
+	case pipeline.StatusFailed:
+		return fmt.Sprintf("⛓ issue #%d: %s stopped, %s", run.Issue, run.Name, run.Error)
+	}
+	return fmt.Sprintf("⛓ issue #%d: %s step %d/%d %s", run.Issue, run.Name, run.Step+1, len(run.Steps), run.Command())
+}
+
+// pipelineMarker is shown after an issue in the Issues tab
+func pipelineMarker(run pipeline.Run) string {
+	switch run.Status {
+	case pipeline.StatusRunning:
+		return fmt.Sprintf("⛓ %d/%d %s", run.Step+1, len(run.Steps), run.Command())
+	case pipeline.StatusFailed:
+		return fmt.Sprintf("⛓ stopped at %s", run.Command())
+	}
+	return ""
+}
+
+// =============================================================================
+// Job queue
+// =============================================================================
+
+// jobStarted reports the launch of a queued command, with the issue's labels
+// once its phase was set
+type jobStarted struct {
+	job    queue.Job
+	labels []string
+	err    error
+}
+
+// dispatchJobs launches the queued commands that fit within the agent limits.
+// The job with ID focus switches to its window when it starts.
+func (m *model) dispatchJobs(focus int) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, job := range m.queue.Dispatch(m.config.Limits, m.agents) {
+		cmds = append(cmds, m.startJob(job, job.ID == focus))
+	}
+	m.clampSelectedJob()
+	return tea.Batch(cmds...)
+}
+
+// startJob launches the command of a job that left the queue. A job that
+// cannot be launched is dropped so it no longer holds a slot, and stops its
+// pipeline when it is a pipeline step.
+func (m *model) startJob(job queue.Job, focus bool) tea.Cmd {
+	q := m.queue
+	return func() tea.Msg {
+		fail := func(err error) tea.Msg {
+			q.Drop(job.ID)
+			if job.Step {
+				return m.failPipeline(job.Repo, job.Issue, err)
+			}
+			return jobStarted{job: job, err: err}
+		}
+		command, ok := m.config.CommandNamed(job.Command)
+		if !ok {
+			return fail(fmt.Errorf("unknown command %s", job.Command))
+		}
+		iss, err := m.fetchIssue(job.Repo, job.Issue)
+		if err != nil {
+			return fail(err)
+		}
+		if err := m.launchCommand(iss, command, focus, command.Advance || job.Step); err != nil {
+			return fail(err)
+		}
+		labels, err := m.setCommandPhase(iss, command)
+		return jobStarted{job: job, labels: labels, err: err}
+	}
+}
+
+// queuePosition returns the 1-based place of a waiting job, or 0 when it is
+// not waiting
+func (m *model) queuePosition(id int) int {
+	for i, job := range m.queue.Jobs() {
+		if job.ID == id && job.Waiting() {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (m *model) selectedJobEntry() (queue.Job, bool) {
+	jobs := m.queue.Jobs()
+	if m.currentTab != tabQueue || m.selectedJob < 0 || m.selectedJob >= len(jobs) {
+		return queue.Job{}, false
+	}
+	return jobs[m.selectedJob], true
+}
+
+func (m *model) moveToNextJob() {
+	if m.currentTab == tabQueue && m.selectedJob < len(m.queue.Jobs())-1 {
+		m.selectedJob++
+	}
+}
+
+func (m *model) moveToPreviousJob() {
+	if m.currentTab == tabQueue && m.selectedJob > 0 {
+		m.selectedJob--
+	}
+}
+
+func (m *model) clampSelectedJob() {
+	if n := len(m.queue.Jobs()); m.selectedJob >= n {
+		m.selectedJob = n - 1
+	}
+	if m.selectedJob < 0 {
+		m.selectedJob = 0
+	}
+}
+
+// moveSelectedJob moves the selected job delta places in the queue, keeping
+// it selected
+func (m *model) moveSelectedJob(delta int) {
+	job, ok := m.selectedJobEntry()
+	if !ok {
+		return
+	}
+	if err := m.queue.Move(job.ID, delta); err != nil {
+		m.err = fmt.Errorf("failed to move %s of issue #%d: %w", job.Command, job.Issue, err)
+		return
+	}
+	if n := m.queuePosition(job.ID); n > 0 {
+		m.selectedJob = n - 1
+	}
+}
+
+// cancelSelectedJob removes the selected job from the queue. Cancelling a
+// pipeline step stops the pipeline.
+func (m *model) cancelSelectedJob() tea.Cmd {
+	job, ok := m.selectedJobEntry()
+	if !ok {
+		return nil
+	}
+	if err := m.queue.Cancel(job.ID); err != nil {
+		m.err = fmt.Errorf("failed to cancel %s of issue #%d: %w", job.Command, job.Issue, err)
+		return nil
+	}
+	m.agentNotice = fmt.Sprintf("⏳ issue #%d: %s cancelled", job.Issue, job.Command)
+	m.clampSelectedJob()
+	if !job.Step {
+		return nil
+	}
+	store := m.pipelines
+	return func() tea.Msg {
+		run, err := store.Fail(job.Repo, job.Issue, job.Command+" was cancelled")
+		if err != nil {
+			return pipelineUpdated{run: run, err: err}
+		}
+		return pipelineUpdated{run: run, removed: true, err: store.Remove(job.Repo, job.Issue)}
+	}
+}
+
+// promoteSelectedJob starts the selected job now, even if that exceeds the
+// agent limits
+func (m *model) promoteSelectedJob() tea.Cmd {
+	job, ok := m.selectedJobEntry()
+	if !ok {
+		return nil
+	}
+	job, err := m.queue.Promote(job.ID)
+	if err != nil {
+		m.err = fmt.Errorf("failed to start %s of issue #%d: %w", job.Command, job.Issue, err)
+		return nil
+	}
+	return m.startJob(job, true)
+}
+
+// queueMarker is shown after an issue in the Issues tab while it has queued
+// commands
+func (m *model) queueMarker(i issue) string {
+	var commands []string
+	for _, job := range m.queue.Jobs() {
+		if job.Waiting() && job.Repo == i.Repo && job.Issue == i.Number {
+			commands = append(commands, job.Command)
+		}
+	}
+	if len(commands) == 0 {
+		return ""
+	}
+	return "⏳ " + strings.Join(commands, " ") + " queued"
+}
+
+// describeLimits summarizes the running agents against the configured limits
+func describeLimits(limits config.Limits, running int) string {
+	s := fmt.Sprintf("%d running", running)
+	switch {
+	case limits.MaxAgents > 0 && limits.MaxPerRepo > 0:
+		s += fmt.Sprintf(", limit %d, %d per repo", limits.MaxAgents, limits.MaxPerRepo)
+	case limits.MaxAgents > 0:
+		s += fmt.Sprintf(", limit %d", limits.MaxAgents)
+	case limits.MaxPerRepo > 0:
+		s += fmt.Sprintf(", %d per repo", limits.MaxPerRepo)
+	case len(limits.Repos) == 0:
+		s += ", no limit"
+	}
+	return s
+}
+
+// fetchIssue returns the current state of an issue from the tracker
+func (m *model) fetchIssue(repo string, number int) (issue, error) {
+	result, err := m.tracker.GetIssue(repo, number)
+	if err != nil {
+		return issue{}, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	iss := issue{Number: result.Number, Title: result.Title, State: result.State, Labels: result.Labels, Repo: result.Repo}
+	if iss.Repo == "" {
+		iss.Repo = repo
+	}
+	return iss, nil
+}
+
+func (m *model) openSelectedIssueInBrowser() tea.Cmd {
+	if m.currentTab == tabIssues && len(m.issues) > 0 && m.selectedIssue < len(m.issues) {
+		issue := m.issues[m.selectedIssue]
+		m.issueURL = fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repo, issue.Number)
+		return openBrowser(m.issueURL)
+	}
+	return nil
+}
+
+// overlayOpen reports whether a dialog or the help overlay is shown, so keys
+// bound to list actions go to it instead.
+func (m *model) overlayOpen() bool {
+	return m.showHelp || m.showConfirmDialog || m.showStopAgentDialog || m.showCommandDialog ||
+		m.showNewIssueDialog || m.showPhaseDialog || m.showPipelineDialog
+}
+
+func (m *model) showCloseIssueDialog() {
+	if m.currentTab == tabIssues && len(m.issues) > 0 && m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+		m.showConfirmDialog = true
+		m.closeWorktree = agent.Worktree{}
+		if m.config.Local.Worktrees {
+			issue := m.issues[m.selectedIssue]
+			// Without a checkout there is nothing to clean up
+			m.closeWorktree, _, _ = agent.FindIssueWorktree(m.config.Local.RepoPath(issue.Repo), issue.Number)
+		}
+	}
+}
+
+// confirmAndCloseIssue closes the issue of the done dialog, and with
+// removeWorktree also removes its worktree; the branch is kept.
+func (m *model) confirmAndCloseIssue(removeWorktree bool) tea.Cmd {
+	if !m.showConfirmDialog || m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		return nil
+	}
+	issue := m.issues[m.selectedIssue]
+	err := closeGitHubIssue(m.tracker, issue.Repo, issue.Number)
+	if err != nil {
+		m.err = fmt.Errorf("failed to close issue: %w", err)
+		m.showConfirmDialog = false
+		m.loading = false
+		return nil
+	}
+	m.showConfirmDialog = false
+	m.loading = true
+	if removeWorktree && m.closeWorktree.Dir != "" {
+		if err := agent.RemoveWorktree(m.config.Local.RepoPath(issue.Repo), m.closeWorktree.Dir); err != nil {
+			m.err = fmt.Errorf("failed to remove worktree: %w", err)
+		} else {
+			m.agentNotice = fmt.Sprintf("■ issue #%d: worktree %s removed", issue.Number, m.closeWorktree.Branch)
+		}
+	}
+
+	return func() tea.Msg {
+		for i := 0; i < 10; i++ {
+			time.Sleep(200 * time.Millisecond)
+			isClosed, checkErr := checkIssueClosed(m.tracker, issue.Repo, issue.Number)
+			if checkErr == nil && isClosed {
+				return m.refresh()
+			}
+		}
+		return m.refresh()
+	}
+}
+
+// executeSelectedCommand queues the selected command for the selected issue;
+// it starts right away unless the agent limits are reached
+func (m *model) executeSelectedCommand() tea.Cmd {
+	if !m.showCommandDialog || m.selectedCommand < 0 || m.selectedCommand >= len(m.config.Commands) {
+		return nil
+	}
+	if m.selectedIssue < 0 || m.selectedIssue >= len(m.issues) {
+		m.showCommandDialog = false
+		m.selectedCommand = -1
+		return nil
+	}
+
+	issue := m.issues[m.selectedIssue]
+	selected := m.config.Commands[m.selectedCommand]
+	m.showCommandDialog = false
+	m.selectedCommand = -1
+
+	if issue.Repo == "" {
+		m.err = fmt.Errorf("no repository associated with this issue")
+		return nil
+	}
+	job := m.queue.Push(issue.Repo, issue.Number, issue.Title, selected.Name())
+	cmd := m.dispatchJobs(job.ID)
+	if n := m.queuePosition(job.ID); n > 0 {
+		m.agentNotice = fmt.Sprintf("⏳ issue #%d: %s queued at position %d", issue.Number, job.Command, n)
+	}
+	return cmd
+}
+
+// launchCommand starts the agent of command for iss in a new tmux window.
+// With trackExit the agent's exit status is written for readExitStatus.
+func (m *model) launchCommand(iss issue, command config.Command, focus, trackExit bool) error {
+	runner := m.config.AgentFor(command)
+	name := command.Name()
+	argv := runner.Command(command.Render(config.IssueVars{Number: iss.Number, Title: iss.Title, Repo: iss.Repo, Labels: iss.Labels}))
+	line := agent.ShellJoin(argv)
+	if trackExit {
+		statusPath := agent.ExitStatusPath(m.exitDir, iss.Repo, iss.Number, name)
+		if err := os.MkdirAll(m.exitDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create exit status dir: %w", err)
+		}
+		os.Remove(statusPath)
+		line = agent.ExitStatusCommand(argv, statusPath)
+	}
+
+	dir := m.config.Local.RepoPath(iss.Repo)
+	if m.config.Local.Worktrees {
+		wt, err := agent.EnsureIssueWorktree(dir, iss.Number, iss.Title)
+		if err != nil {
+			return fmt.Errorf("failed to prepare worktree: %w", err)
+		}
+		dir = wt.Dir
+	}
+
+	return m.launcher.Launch(Launch{
+		Repo:    iss.Repo,
+		Dir:     dir,
+		Window:  fmt.Sprintf("%s-%s-%d", runner.WindowPrefix(), name, iss.Number),
+		Command: line,
+		Focus:   focus,
+	})
+}
+
+// setCommandPhase sets the phase of command on iss, if it has one the repo's
+// workflow knows, and returns the issue's labels.
+func (m *model) setCommandPhase(iss issue, command config.Command) ([]string, error) {
+	workflow := m.config.Workflow(iss.Repo)
+	if phase, ok := workflow.Phase(command.Phase); ok {
+		return setPhase(m.tracker, workflow, iss.Repo, iss.Number, iss.Labels, phase)
+	}
+	return iss.Labels, nil
+}
+
+// Launch describes an agent command to start in a tmux window
+type Launch struct {
+	// Repo is the issue's repository, "owner/name"; it selects the session
+	Repo string
+	// Dir is the working directory of the window
+	Dir    string
+	Window string
+	// Command is the shell line typed into the window
+	Command string
+	// Focus switches the tmux client to the new window
+	Focus bool
+}
+
+// Launcher starts agent commands
+type Launcher interface {
+	Launch(l Launch) error
+}
+
+// tmuxLauncher opens a window in the repo's tmux session, starting the session
+// through tmuxinator when one of its projects is rooted in the repo
+type tmuxLauncher struct{}
+
+func (tmuxLauncher) Launch(l Launch) error {
+	muxProject := findMatchingTmuxinatorSession(l.Repo)
+	sessionName := muxProject
+	if sessionName == "" {
+		sessionName = strings.ReplaceAll(l.Repo, "/", "-")
+	}
+
+	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	if err := checkCmd.Run(); err != nil {
+		if muxProject != "" {
+			startCmd := exec.Command("tmuxinator", "start", muxProject, "-d")
+			if err := startCmd.Run(); err != nil {
+				return fmt.Errorf("failed to start tmuxinator project: %w", err)
+			}
+		} else {
+			createCmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-n", "main")
+			if err := createCmd.Run(); err != nil {
+				return fmt.Errorf("failed to create tmux session: %w", err)
+			}
+		}
+	}
+
+	cmd := exec.Command("tmux", "new-window", "-d", "-n", l.Window, "-t", sessionName, "-c", l.Dir)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to create tmux window: %w", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	target := fmt.Sprintf("%s:%s", sessionName, l.Window)
+	cmd = exec.Command("tmux", "send-keys", "-t", target, l.Command, "Enter")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	if l.Focus {
+		selectCmd := exec.Command("bash", "-c", fmt.Sprintf("tmux select-window -t %s && tmux switch-client -t %s", target, sessionName))
+		_ = selectCmd.Run()
+	}
+	return nil
+}
+
+func (m *model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	if m.width < minWidth || m.height < minHeight {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+			errorStyle.Render(fmt.Sprintf("Terminal too small (%dx%d)\nMinimum: %dx%d", m.width, m.height, minWidth, minHeight)))
+	}
+
+	var s strings.Builder
+
+	s.WriteString(m.renderHeader())
+	s.WriteString("\n")
+
+	contentHeight := m.height - headerHeight - footerHeight
+	s.WriteString(m.renderContent(m.width, contentHeight))
+	s.WriteString("\n")
+
+	s.WriteString(m.renderFooter())
+
+	if m.showNewIssueDialog {
+		return m.renderNewIssueDialogOverlay(s.String())
+	}
+
+	if m.showConfirmDialog {
+		return m.renderConfirmDialog(s.String())
+	}
+
+	if m.showStopAgentDialog {
+		return m.renderStopAgentDialog(s.String())
+	}
+
+	if m.showCommandDialog {
+		return m.renderCommandDialog(s.String())
+	}
+
+	if m.showPhaseDialog {
+		return m.renderPhaseDialog(s.String())
+	}
+
+	if m.showPipelineDialog {
+		return m.renderPipelineDialog(s.String())
+	}
+
+	if m.showHelp {
+		return m.renderHelpOverlay(s.String())
+	}
+
+	return s.String()
+}
+
+func (m *model) renderHeader() string {
+	title := titleStyle.Render(" AI Monitor")
+
+	var tabs []string
+	for i, name := range tabNames {
+		isActive := i == m.currentTab
+		if n := m.queue.Waiting(); i == tabQueue && n > 0 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+		tabStr := name
+		if isActive {
+			tabStr = tabActiveStyle.Render(" " + name + " ")
+		} else {
+			tabStr = tabInactiveStyle.Render(" " + name + " ")
+		}
+		tabs = append(tabs, tabStr)
+	}
+	tabBar := strings.Join(tabs, " ")
+
+	attention := ""
+	if n := agent.CountNeedingAttention(m.agents); n > 0 {
+		noun := "agents need"
+		if n == 1 {
+			noun = "agent needs"
+		}
+		attention = agentWaitingStyle.Render(fmt.Sprintf("⚠ %d %s attention (w)", n, noun))
+	}
+
+	titleWidth := lipgloss.Width(title)
+	tabWidth := lipgloss.Width(tabBar)
+	attentionWidth := lipgloss.Width(attention)
+	spacing := m.width - titleWidth - tabWidth - attentionWidth - 1
+	if spacing < 1 {
+		spacing = 1
+	}
+
+	header := title + strings.Repeat(" ", spacing/2) + tabBar
+	if attention != "" {
+		header += strings.Repeat(" ", spacing-spacing/2) + attention
+	}
+	return headerBarStyle.Width(m.width).Render(header)
+}
+
+func (m *model) renderContent(width, height int) string {
+	if m.loading {
+		spinner := spinners[m.spinner]
+		msg := spinner + " Loading..."
+		return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+			statusStyle.Render(msg))
+	}
+
+	var s strings.Builder
+
+	switch m.currentTab {
+	case tabAgents:
+		s.WriteString(m.renderAgentsView())
+	case tabTimeline:
+		s.WriteString(m.renderTimelineView())
+	case tabQueue:
+		s.WriteString(m.renderQueueView())
+	default:
+		s.WriteString(m.renderIssuesView())
+	}
+
+	if m.err != nil {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		s.WriteString("\n")
+	}
+
+	if m.scanErr != nil {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.scanErr)))
+		s.WriteString("\n")
+	}
+
+	if m.currentTab == tabAgents && !m.hidePanePreview {
+		remaining := height - lipgloss.Height(s.String())
+		s.WriteString(m.renderPanePreview(width, remaining))
+	}
+
+	content := s.String()
+	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
+}
+
+func (m *model) renderAgentsView() string {
+	var s strings.Builder
+
+	agentsToShow := m.agents
+	if m.filterActive {
+		agentsToShow = agent.FilterActive(m.agents, true)
+		s.WriteString(mutedStyle.Render("  [Filtering: active only]"))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(sectionTitleStyle.Render("🤖 Running Agents"))
+	s.WriteString("\n")
+
+	if m.agentNotice != "" {
+		s.WriteString(mutedStyle.Render("  " + m.agentNotice))
+		s.WriteString("\n")
+	}
+
+	if len(agentsToShow) == 0 && m.err == nil && m.scanErr == nil {
+		s.WriteString(itemStyle.Render("  No agents running"))
+		s.WriteString("\n")
+	} else if len(agentsToShow) > 0 {
+		selectedPID := -1
+		if selected, ok := m.selectedAgentEntry(); ok {
+			selectedPID = selected.PID
+		}
+
+		s.WriteString(mutedStyle.Render(m.renderAgentTableHeader()))
+		s.WriteString("\n")
+
+		grouped := m.agentGroups(agentsToShow)
+		for _, kind := range sortedKindKeys(grouped) {
+			s.WriteString(itemStyle.Render(fmt.Sprintf("  %s (%d)", kind, len(grouped[kind]))))
+			s.WriteString("\n")
+			for _, a := range grouped[kind] {
+				prefix := "    "
+				currentStyle := itemStyle
+				if a.PID == selectedPID {
+					prefix = "  > "
+					currentStyle = selectedItemStyle
+				}
+				s.WriteString(currentStyle.Render(fmt.Sprintf("%s%s", prefix, formatAgentColumns(a))))
+				s.WriteString(agentStateStyle(a.State).Render(fmt.Sprintf("%-8s", a.State.String())))
+				s.WriteString(currentStyle.UnsetPadding().Render(getRepoName(a.WorkingDir)))
+				if a.Issue > 0 {
+					s.WriteString(agentBadgeStyle.Render(fmt.Sprintf(" #%d %s", a.Issue, a.Command)))
+				}
+				if a.InPane() {
+					s.WriteString(mutedStyle.Render(" " + a.Pane.String()))
+				}
+				s.WriteString("\n")
+				if a.Git != nil {
+					s.WriteString(m.renderAgentGitStatus(a))
+				}
+			}
+		}
+	}
+
+	return s.String()
+}
+
+// renderAgentGitStatus renders the git summary line below an agent row, and
+// the changed files when the row is expanded
+func (m *model) renderAgentGitStatus(a agent.Agent) string {
+	var s strings.Builder
+	g := a.Git
+
+	branch := g.Branch
+	if branch == "" || branch == "(detached)" {
+		branch = "detached"
+	}
+	summary := "⎇ " + branch
+	if g.HasUpstream {
+		summary += fmt.Sprintf(" ↑%d ↓%d", g.Ahead, g.Behind)
+	}
+	dirtyStyle := mutedStyle
+	if g.Dirty() > 0 {
+		dirtyStyle = gitDirtyStyle
+	}
+
+	expandHint := ""
+	if g.Dirty() > 0 {
+		expandHint = " ▸"
+		if m.expandedAgents[a.PID] {
+			expandHint = " ▾"
+		}
+	}
+
+	s.WriteString(mutedStyle.Render(fmt.Sprintf("        %s · ", summary)))
+	s.WriteString(dirtyStyle.Render(fmt.Sprintf("%d dirty%s", g.Dirty(), expandHint)))
+	if g.LastCommit != "" {
+		s.WriteString(mutedStyle.Render(" · " + truncate(g.LastCommit, gitSubjectTruncate)))
+	}
+	s.WriteString("\n")
+
+	if m.expandedAgents[a.PID] {
+		for _, f := range g.Files {
+			s.WriteString(gitDirtyStyle.Render(fmt.Sprintf("          %s", f.XY)))
+			s.WriteString(mutedStyle.Render(" " + f.Path))
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+// toggleSelectedAgentExpanded shows or hides the changed files of the selected agent
+func (m *model) toggleSelectedAgentExpanded() {
+	if m.currentTab != tabAgents {
+		return
+	}
+	a, ok := m.selectedAgentEntry()
+	if !ok {
+		return
+	}
+	if m.expandedAgents == nil {
+		m.expandedAgents = make(map[int]bool)
+	}
+	m.expandedAgents[a.PID] = !m.expandedAgents[a.PID]
+}
+
+// renderAgentTableHeader renders the column titles, marking the sort column
+func (m *model) renderAgentTableHeader() string {
+	titles := make([]string, numAgentSortColumns)
+	for i, name := range agentSortColumnNames {
+		if i == m.agentSortColumn {
+			if m.agentSortDesc {
+				name += "↓"
+			} else {
+				name += "↑"
+			}
+		}
+		titles[i] = name
+	}
+	return fmt.Sprintf("      %-8s %-7s %-6s %-7s %-6s  %-8s%s",
+		titles[agentSortPID], titles[agentSortUptime], titles[agentSortCPU],
+		titles[agentSortMemory], titles[agentSortChildren], "STATE", "REPO")
+}
+
+// formatAgentColumns renders the resource columns of an agent row
+func formatAgentColumns(a agent.Agent) string {
+	uptime := "-"
+	if !a.StartTime.IsZero() {
+		uptime = formatUptime(time.Since(a.StartTime))
+	}
+	return fmt.Sprintf("%-8d %-7s %-6.1f %-7s %-6d",
+		a.PID, uptime, a.CPUPercent, formatBytes(a.RSS), len(a.SubProcesses))
+}
+
+// renderPanePreview renders the tail of the selected agent's pane in a box
+// filling the given height
+func (m *model) renderPanePreview(width, height int) string {
+	a, ok := m.selectedAgentEntry()
+	if !ok || height < previewMinHeight {
+		return ""
+	}
+
+	title := fmt.Sprintf("Preview: %s [%d]", getRepoName(a.WorkingDir), a.PID)
+	body := ""
+	switch {
+	case !a.InPane():
+		body = mutedStyle.Render("Agent is not running in a tmux pane")
+	case m.panePreviewTarget != a.Pane.Target():
+		body = mutedStyle.Render(spinners[m.spinner] + " Capturing pane...")
+	case m.panePreviewErr != nil:
+		body = errorStyle.Render(fmt.Sprintf("Error: %v", m.panePreviewErr))
+	default:
+		title = fmt.Sprintf("Preview: %s", a.Pane)
+		innerWidth := width - 4
+		lines := strings.Split(agent.LastLines(m.panePreview, height-3), "\n")
+		for i, line := range lines {
+			lines[i] = truncate(line, innerWidth)
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	content := panePreviewTitleStyle.Render(title) + "\n" + body
+	return panePreviewStyle.Width(width - 2).Height(height - 2).MaxHeight(height).Render(content)
+}
+
+func (m *model) renderIssuesView() string {
+	var s strings.Builder
+
+	s.WriteString(sectionTitleStyle.Render("📋 GitHub Issues"))
+	if total := m.issuesTotal - m.issuesExcluded; total > 0 {
+		count := fmt.Sprintf(" showing %d of %d", len(m.issues), total)
+		if m.loadingMoreIssues {
+			count += ", loading more…"
+		} else if m.issuesNextPage == 0 && len(m.issues) < total {
+			count += " (search limit reached)"
+		}
+		s.WriteString(mutedStyle.Render(count))
+	}
+	s.WriteString("\n")
+
+	if len(m.issues) == 0 && m.err == nil {
+		s.WriteString(itemStyle.Render("  No issues found"))
+		s.WriteString("\n")
+	} else if len(m.issues) > 0 {
+		grouped := groupIssuesByRepo(m.issues)
+		repoNames := sortedRepoKeys(grouped)
+
+		for _, repoName := range repoNames {
+			issues := grouped[repoName]
+			s.WriteString(itemStyle.Render(fmt.Sprintf("  📁 %s", repoName)))
+			s.WriteString("\n")
+
+			for _, i := range issues {
+				workflow := m.config.Workflow(i.Repo)
+				labelsWidth := calculateLabelsWidth(workflow, i.Labels)
+				labels := ""
+				phase := ""
+				var otherLabels []string
+				for _, l := range i.Labels {
+					if workflow.IsPhase(l) {
+						phase = phaseLabelStyle.Render(fmt.Sprintf("(%s)", l))
+					} else {
+						otherLabels = append(otherLabels, labelStyle.Render(l))
+					}
+				}
+				if len(otherLabels) > 0 {
+					labels = " [" + strings.Join(otherLabels, ", ") + "]"
+				}
+				badge := ""
+				if marker := m.queueMarker(i); marker != "" {
+					labelsWidth += len(marker) + 1
+					badge += " " + mutedStyle.Render(marker)
+				}
+				if marker := pipelineMarker(m.pipelineRuns[pipeline.Key(i.Repo, i.Number)]); marker != "" {
+					labelsWidth += len(marker) + 1
+					badge += " " + mutedStyle.Render(marker)
+				}
+				if a, ok := m.agentForIssue(i); ok {
+					badgeText := " agent running"
+					if a.Command != "" {
+						badgeText += ": " + a.Command
+					}
+					labelsWidth += len(badgeText) + 1
+					badge += " " + agentBadgeStyle.Render("⚡"+badgeText)
+				}
+				maxTitleWidth := calculateMaxTitleWidth(m.width, labelsWidth)
+
+				prefix := "    "
+				currentStyle := itemStyle
+				selectedIssuePtr := -1
+				if m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+					selectedIssuePtr = m.issues[m.selectedIssue].Number
+				}
+				if selectedIssuePtr == i.Number && m.issues[m.selectedIssue].Repo == i.Repo {
+					prefix = "  > "
+					currentStyle = selectedItemStyle
+				}
+
+				s.WriteString(currentStyle.Render(fmt.Sprintf("%s#%d %s%s%s%s", prefix, i.Number, truncate(i.Title, maxTitleWidth), labels, phase, badge)))
+				s.WriteString("\n")
+			}
+		}
+	}
+
+	return s.String()
+}
+
+// renderTimelineView lists recorded agent sessions per repo, newest first,
+// with the total time spent on each linked issue
+func (m *model) renderTimelineView() string {
+	var s strings.Builder
+
+	s.WriteString(sectionTitleStyle.Render("📜 Agent Timeline"))
+	s.WriteString("\n")
+
+	if m.recorder == nil {
+		s.WriteString(itemStyle.Render("  History is disabled"))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	now := time.Now()
+	sessions := m.historySessions
+	if len(sessions) == 0 {
+		s.WriteString(itemStyle.Render("  No agent sessions recorded yet"))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	byRepo := make(map[string][]history.Session)
+	for _, session := range sessions {
+		byRepo[session.Repo] = append(byRepo[session.Repo], session)
+	}
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	totals := history.TotalsByIssue(sessions, now)
+	for _, repo := range repos {
+		name := repo
+		if idx := strings.Index(name, "/"); idx > 0 {
+			name = name[idx+1:]
+		}
+		if name == "" {
+			name = "(no repo)"
+		}
+		s.WriteString(itemStyle.Render("📁 " + name))
+		s.WriteString("\n")
+
+		for _, t := range totals {
+			if t.Repo != repo {
+				continue
+			}
+			noun := "sessions"
+			if t.Sessions == 1 {
+				noun = "session"
+			}
+			line := fmt.Sprintf("    #%d  %s total, %s busy, %d %s",
+				t.Issue, formatUptime(t.Duration), formatUptime(t.Busy), t.Sessions, noun)
+			s.WriteString(itemStyle.Render(line))
+			s.WriteString("\n")
+		}
+
+		repoSessions := byRepo[repo]
+		if len(repoSessions) > timelineMaxSessions {
+			repoSessions = repoSessions[:timelineMaxSessions]
+		}
+		for _, session := range repoSessions {
+			s.WriteString(mutedStyle.Render("    " + formatTimelineSession(session, now)))
+			s.WriteString("\n")
+		}
+		if hidden := len(byRepo[repo]) - len(repoSessions); hidden > 0 {
+			s.WriteString(mutedStyle.Render(fmt.Sprintf("    … %d older sessions", hidden)))
+			s.WriteString("\n")
+		}
+	}
+
+	return s.String()
+}
+
+// formatTimelineSession renders one session, e.g.
+// "2026-10-16 14:02  45m  OpenCode #42 /tdd  (running)"
+func formatTimelineSession(session history.Session, now time.Time) string {
+	line := fmt.Sprintf("%s  %-6s %s", session.Start.Local().Format("2006-01-02 15:04"),
+		formatUptime(session.Duration(now)), session.Kind)
+	if session.Issue > 0 {
+		line += fmt.Sprintf(" #%d", session.Issue)
+	}
+	if session.Command != "" {
+		line += " " + session.Command
+	}
+	if session.Running() {
+		line += "  (running)"
+	}
+	return line
+}
+
+// renderQueueView lists the queued commands in the order they start, then
+// those still waiting for their agent to show up
+func (m *model) renderQueueView() string {
+	var s strings.Builder
+
+	s.WriteString(sectionTitleStyle.Render("⏳ Agent Queue"))
+	s.WriteString(mutedStyle.Render(" " + describeLimits(m.config.Limits, len(m.agents))))
+	s.WriteString("\n")
+
+	if m.agentNotice != "" {
+		s.WriteString(mutedStyle.Render("  " + m.agentNotice))
+		s.WriteString("\n")
+	}
+
+	jobs := m.queue.Jobs()
+	if len(jobs) == 0 {
+		s.WriteString(itemStyle.Render("  No queued commands"))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	now := time.Now()
+	for i, job := range jobs {
+		prefix := "    "
+		currentStyle := itemStyle
+		if i == m.selectedJob {
+			prefix = "  > "
+			currentStyle = selectedItemStyle
+		}
+		place := fmt.Sprintf("%d.", i+1)
+		if !job.Waiting() {
+			place = "starting"
+		}
+		line := fmt.Sprintf("%s%-9s %s #%d %s  %s", prefix, place, job.Repo, job.Issue, job.Command, truncate(job.Title, 40))
+		s.WriteString(currentStyle.Render(line))
+		s.WriteString(mutedStyle.Render("  " + formatUptime(now.Sub(job.Queued))))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+func (m *model) renderFooter() string {
+	filterStatus := "a: all"
+	if m.filterActive {
+		filterStatus = "a: active"
+	}
+	hints := []string{
+		"1-4: tab",
+		"r: refresh",
+		filterStatus,
+		"q: quit",
+		"?: help",
+	}
+
+	// Add vim navigation hints when on Issues tab
+	if m.currentTab == tabIssues && len(m.issues) > 0 {
+		hints = append(hints, "j/k: nav")
+		hints = append(hints, "o: open")
+		hints = append(hints, "d: done")
+		hints = append(hints, "n: new")
+		hints = append(hints, "p: phase")
+	}
+
+	if m.currentTab == tabAgents && len(m.agents) > 0 {
+		hints = append(hints, "j/k: nav")
+		hints = append(hints, "enter: jump")
+		hints = append(hints, "s: sort")
+		hints = append(hints, "v: preview")
+		hints = append(hints, "e: files")
+		hints = append(hints, "w: waiting")
+		hints = append(hints, "i: interrupt")
+		hints = append(hints, "x: stop")
+		hints = append(hints, "R: restart")
+	}
+
+	if m.currentTab == tabQueue && m.queue.Waiting() > 0 {
+		hints = append(hints, "j/k: nav")
+		hints = append(hints, "J/K: move")
+		hints = append(hints, "x: cancel")
+		hints = append(hints, "enter: start now")
+	}
+
+	// Keep the footer on one line: drop the tab, refresh and filter hints to
+	// make room for the tab's own keys, then the last hints; the help overlay
+	// lists every key
+	for len(hints) > 2 && lipgloss.Width(strings.Join(hints, "  ")) > m.width-2 {
+		if hints[0] != "q: quit" {
+			hints = hints[1:]
+		} else {
+			hints = hints[:len(hints)-1]
+		}
+	}
+
+	hintStr := hints[0]
+	for i := 1; i < len(hints); i++ {
+		hintStr += "  " + hints[i]
+	}
+
+	footer := keyHintStyle.Render(hintStr)
+	return footerBarStyle.Width(m.width).Render(footer)
+}
+
+func (m *model) renderHelpOverlay(content string) string {
+	var s strings.Builder
+
+	s.WriteString(helpTitleStyle.Render("Keyboard Shortcuts"))
+	s.WriteString("\n")
+
+	if m.helpSearch != "" {
+		s.WriteString(mutedStyle.Render("Search: "))
+		s.WriteString(helpKeyStyle.Render(m.helpSearch))
+		s.WriteString("\n\n")
+	}
+
+	for _, cmd := range m.helpMatches {
+		s.WriteString(helpItemStyle.Render("  " + cmd))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(mutedStyle.Render("  esc: close"))
+
+	helpContent := helpModalStyle.Render(s.String())
+
+	helpWidth := 50
+	helpHeight := len(m.helpMatches) + 8
+	if helpWidth > m.width-4 {
+		helpWidth = m.width - 4
+	}
+	if helpHeight > m.height-4 {
+		helpHeight = m.height - 4
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpContent)
+}
+
+func (m *model) renderNewIssueDialogOverlay(content string) string {
+	dialog := m.renderNewIssueDialogRaw(m.width, m.height)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
+}
+
+func (m *model) renderConfirmDialog(content string) string {
+	var s strings.Builder
+
+	issueNum := 0
+	issueTitle := ""
+	if m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+		issueNum = m.issues[m.selectedIssue].Number
+		issueTitle = m.issues[m.selectedIssue].Title
+	}
+
+	s.WriteString(confirmDialogTitleStyle.Render("Stäng issue i GitHub"))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogOptionStyle.Render(fmt.Sprintf("  Issue #%d: %s", issueNum, truncate(issueTitle, confirmTitleTruncate))))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogOptionStyle.Render("  Bekräfta?"))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogHighlightStyle.Render("  [Ja] Enter / y"))
+	s.WriteString("\n")
+	if m.closeWorktree.Dir != "" {
+		s.WriteString(confirmDialogOptionStyle.Render("  [Ja, ta bort worktree] w"))
+		s.WriteString("\n")
+		s.WriteString(mutedStyle.Render("    " + truncate(m.closeWorktree.Branch, confirmTitleTruncate)))
+		s.WriteString("\n")
+	}
+	s.WriteString(confirmDialogOptionStyle.Render("  [Nej] n / Esc"))
+
+	confirmContent := confirmDialogStyle.Render(s.String())
+
+	actualDialogWidth := dialogWidth
+	actualDialogHeight := dialogHeight
+	if actualDialogWidth > m.width-4 {
+		actualDialogWidth = m.width - 4
+	}
+	if actualDialogHeight > m.height-4 {
+		actualDialogHeight = m.height - 4
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, confirmContent)
+}
+
+func (m *model) renderStopAgentDialog(content string) string {
+	var s strings.Builder
+
+	a := m.stopAgentTarget
+
+	s.WriteString(confirmDialogTitleStyle.Render("Stoppa agent"))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogOptionStyle.Render(fmt.Sprintf("  %s [%d] @ %s", a.Name, a.PID, truncate(getRepoName(a.WorkingDir), confirmTitleTruncate))))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogOptionStyle.Render("  Skicka SIGTERM?"))
+	s.WriteString("\n\n")
+	s.WriteString(confirmDialogHighlightStyle.Render("  [Ja] Enter / y"))
+	s.WriteString("\n")
+	s.WriteString(confirmDialogOptionStyle.Render("  [Nej] n / Esc"))
+
+	confirmContent := confirmDialogStyle.Render(s.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, confirmContent)
+}
+
+func (m *model) renderCommandDialog(content string) string {
+	var s strings.Builder
+
+	issueNum := 0
+	issueTitle := ""
+	if m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+		issueNum = m.issues[m.selectedIssue].Number
+		issueTitle = m.issues[m.selectedIssue].Title
+	}
+
+	s.WriteString(commandDialogTitleStyle.Render("Välj kommando för issue #" + fmt.Sprint(issueNum)))
+	s.WriteString("\n\n")
+	s.WriteString(commandDialogItemStyle.Render("  " + truncate(issueTitle, 30)))
+	s.WriteString("\n\n")
+
+	for i, c := range m.config.Commands {
+		number := "  "
+		if i < maxQuickSelect {
+			number = fmt.Sprintf("%d.", i+1)
+		}
+		if i == m.selectedCommand {
+			s.WriteString(commandDialogSelectedStyle.Render(fmt.Sprintf("  > %s %s ", number, c.Label)))
+		} else {
+			s.WriteString(commandDialogItemStyle.Render(fmt.Sprintf("    %s %s", number, c.Label)))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(commandDialogHintStyle.Render("  Enter: Kör  |  ↑↓: Navigera  |  " + quickSelectHint(len(m.config.Commands)) + "  |  Esc: Avbryt"))
+
+	commandContent := commandDialogStyle.Render(s.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, commandContent)
+}
+
+func (m *model) renderPipelineDialog(content string) string {
+	var s strings.Builder
+
+	issueNum := 0
+	issueTitle := ""
+	var run pipeline.Run
+	if m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+		issueNum = m.issues[m.selectedIssue].Number
+		issueTitle = m.issues[m.selectedIssue].Title
+		run = m.pipelineRuns[pipeline.Key(m.issues[m.selectedIssue].Repo, issueNum)]
+	}
+
+	s.WriteString(commandDialogTitleStyle.Render("Välj pipeline för issue #" + fmt.Sprint(issueNum)))
+	s.WriteString("\n\n")
+	s.WriteString(commandDialogItemStyle.Render("  " + truncate(issueTitle, 30)))
+	s.WriteString("\n\n")
+
+	for i, p := range m.config.Pipelines {
+		number := "  "
+		if i < maxQuickSelect {
+			number = fmt.Sprintf("%d.", i+1)
+		}
+		name := p.Name
+		if run.Status == pipeline.StatusFailed && run.Name == p.Name {
+			name += " (fortsätt vid " + run.Command() + ")"
+		}
+		if i == m.selectedPipeline {
+			s.WriteString(commandDialogSelectedStyle.Render(fmt.Sprintf("  > %s %s ", number, name)))
+		} else {
+			s.WriteString(commandDialogItemStyle.Render(fmt.Sprintf("    %s %s", number, name)))
+		}
+		s.WriteString("\n")
+	}
+	if m.selectedPipeline >= 0 && m.selectedPipeline < len(m.config.Pipelines) {
+		s.WriteString("\n")
+		steps := []rune(strings.Join(m.config.Pipelines[m.selectedPipeline].Steps, " → "))
+		if limit := commandDialogWidth - 4; len(steps) > limit {
+			steps = append(steps[:limit-1], '…')
+		}
+		s.WriteString(commandDialogHintStyle.Render("  " + string(steps)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(commandDialogHintStyle.Render("  Enter: Starta  |  ↑↓: Navigera  |  " + quickSelectHint(len(m.config.Pipelines)) + "  |  Esc: Avbryt"))
+
+	pipelineContent := commandDialogStyle.Render(s.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, pipelineContent)
+}
+
+func (m *model) renderPhaseDialog(content string) string {
+	var s strings.Builder
+
+	issueNum := 0
+	issueTitle := ""
+	if m.selectedIssue >= 0 && m.selectedIssue < len(m.issues) {
+		issueNum = m.issues[m.selectedIssue].Number
+		issueTitle = m.issues[m.selectedIssue].Title
+	}
+
+	s.WriteString(commandDialogTitleStyle.Render("Välj fas för issue #" + fmt.Sprint(issueNum)))
+	s.WriteString("\n\n")
+	s.WriteString(commandDialogItemStyle.Render("  " + truncate(issueTitle, 30)))
+	s.WriteString("\n\n")
+
+	for i, phase := range m.phaseOptions {
+		desc := phase.Title
+		if desc == "" {
+			desc = phase.Description
+		}
+		if desc == "" {
+			desc = phase.Label
+		}
+		number := "  "
+		if i < maxQuickSelect {
+			number = fmt.Sprintf("%d.", i+1)
+		}
+		if i == m.selectedPhase {
+			s.WriteString(commandDialogSelectedStyle.Render(fmt.Sprintf("  > %s %s ", number, desc)))
+		} else {
+			s.WriteString(commandDialogItemStyle.Render(fmt.Sprintf("    %s %s", number, desc)))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(commandDialogHintStyle.Render("  Enter: Välj  |  ↑↓: Navigera  |  " + quickSelectHint(len(m.phaseOptions)) + "  |  Esc: Avbryt"))
+
+	phaseContent := commandDialogStyle.Render(s.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, phaseContent)
+}
+
+// truncate cuts s to maxLen terminal cells, ending in "..." when it was cut.
+// It counts display width rather than bytes, so multibyte characters such as
+// box drawing in captured panes are never split.
+func truncate(s string, maxLen int) string {
+	return ansi.Truncate(s, maxLen, "...")
+}
+
+func calculateMaxTitleWidth(terminalWidth, labelsWidth int) int {
+	available := terminalWidth - issuePrefixWidth - labelsWidth - issuePadding
+	if available < issueMinTitleWidth {
+		return issueMinTitleWidth
+	}
+	return available
+}
+
+func calculateLabelsWidth(workflow config.Workflow, labels []string) int {
+	if len(labels) == 0 {
+		return 0
+	}
+	nonPhaseLabels := filterNonPhaseLabels(workflow, labels)
+	if len(nonPhaseLabels) == 0 {
+		return 0
+	}
+	width := len(nonPhaseLabels) + 2
+	for _, l := range nonPhaseLabels {
+		width += len(l)
+	}
+	return width
+}
+
+func filterNonPhaseLabels(workflow config.Workflow, labels []string) []string {
+	var result []string
+	for _, l := range labels {
+		if !workflow.IsPhase(l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func getRepoName(path string) string {
+	if path == "" || path == specialPathWildcard || path == specialPathStart || path == specialPathStdio {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) > 0 {
+		return parts[len(parts)-1]
+	}
+	return path
+}
+
+type refreshComplete struct {
+	agents  []agent.Agent
+	issues  issuePage
+	history []history.Event
+	// runs is nil when the pipeline state could not be read
+	runs []pipeline.Run
+	// detected is set when agents is the full list of running agents
+	detected bool
+	err      error
+}
+
+func (m *model) refresh() tea.Msg {
+	agents, err := m.detector.Detect()
+	issues, fetchErr := fetchIssuePages(m.tracker, m.config.GitHub, m.issuesPages)
+	events, historyErr := m.recordHistory(agents, err)
+	runs, pipelineErr := m.pipelines.Runs()
+	result := refreshComplete{
+		agents:   agents,
+		issues:   issues,
+		history:  events,
+		runs:     runs,
+		detected: err == nil || errors.Is(err, agent.ErrNoAgentsFound),
+	}
+
+	if err != nil {
+		result.err = fmt.Errorf("agent detection failed: %w", err)
+		return result
+	}
+
+	if fetchErr != nil {
+		result.err = fetchErr
+		return result
+	}
+
+	if historyErr != nil {
+		result.err = historyErr
+		return result
+	}
+
+	result.err = pipelineErr
+	return result
+}
+
+// recordHistory feeds a detection result to the history recorder and returns
+// the new events. Failed detections other than "no agents" are not recorded,
+// so a transient error does not end every session.
+func (m *model) recordHistory(agents []agent.Agent, detectErr error) ([]history.Event, error) {
+	if m.recorder == nil || (detectErr != nil && !errors.Is(detectErr, agent.ErrNoAgentsFound)) {
+		return nil, nil
+	}
+	events, err := m.recorder.Observe(agents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+	return events, nil
+}
+
+// addHistory adds newly recorded events, keeps as many as the recorder does
+// and folds them into sessions for the timeline
+func (m *model) addHistory(events []history.Event) {
+	if len(events) == 0 {
+		return
+	}
+	n := len(m.historyEvents)
+	m.historyEvents = append(m.historyEvents, events...)
+	// Scans recorded concurrently by refresh and the watcher may arrive out of order
+	if n > 0 && events[0].Time.Before(m.historyEvents[n-1].Time) {
+		sort.SliceStable(m.historyEvents, func(i, j int) bool {
+			return m.historyEvents[i].Time.Before(m.historyEvents[j].Time)
+		})
+	}
+	if limit := m.recorder.MaxEvents(); limit > 0 && len(m.historyEvents) > limit {
+		m.historyEvents = m.historyEvents[len(m.historyEvents)-limit:]
+	}
+	m.historySessions = history.Sessions(m.historyEvents, time.Now())
+}
+
+// issuePage is one page of open issues and where the search continues
+type issuePage struct {
+	issues   []issue
+	total    int
+	nextPage int
+	// excluded counts issues of the page dropped by the exclusion globs
+	excluded int
+	// pages is how many search pages the issues come from
+	pages int
+}
+
+// fetchIssuePages fetches the first pages pages of open issues, at least one,
+// so a refresh keeps the issues loaded while scrolling
+func fetchIssuePages(tracker github.Tracker, sources config.GitHub, pages int) (issuePage, error) {
+	result, err := fetchIssuePage(tracker, sources, 1)
+	if err != nil {
+		return issuePage{}, err
+	}
+	for result.pages < pages && result.nextPage != 0 {
+		next, err := fetchIssuePage(tracker, sources, result.nextPage)
+		if err != nil {
+			return issuePage{}, err
+		}
+		result.issues = appendNewIssues(result.issues, next.issues)
+		result.total = next.total
+		result.nextPage = next.nextPage
+		result.excluded += next.excluded
+		result.pages++
+	}
+	return result, nil
+}
+
+func fetchIssuePage(tracker github.Tracker, sources config.GitHub, page int) (issuePage, error) {
+	result, err := tracker.SearchIssues(sources.SearchQuery(), page)
+	if err != nil {
+		return issuePage{}, err
+	}
+
+	var allIssues []issue
+	excluded := 0
+	for _, result := range result.Issues {
+		if sources.Excluded(result.Repo) {
+			excluded++
+			continue
+		}
+		allIssues = append(allIssues, issue{
+			Number: result.Number,
+			Title:  result.Title,
+			State:  result.State,
+			Labels: result.Labels,
+			Repo:   result.Repo,
+		})
+	}
+
+	return issuePage{issues: allIssues, total: result.Total, nextPage: result.NextPage, excluded: excluded, pages: 1}, nil
+}
+
+// openBrowser opens a URL in the default browser
+func openBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		for _, cmd := range browserCommands {
+			err := exec.Command(cmd, url).Run()
+			if err == nil {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// closeGitHubIssue closes an issue in GitHub
+func closeGitHubIssue(tracker github.Tracker, repo string, number int) error {
+	if err := tracker.CloseIssue(repo, number); err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return fmt.Errorf("issue #%d not found in %s", number, repo)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkIssueClosed verifies an issue is closed in GitHub
+func checkIssueClosed(tracker github.Tracker, repo string, number int) (bool, error) {
+	result, err := tracker.GetIssue(repo, number)
+	if err != nil {
+		return false, err
+	}
+	return result.State == "closed", nil
+}
+
+// addIssueLabel adds a label to an issue in GitHub
+func addIssueLabel(tracker github.Tracker, repo string, number int, label string) error {
+	if err := tracker.AddLabels(repo, number, label); err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return fmt.Errorf("issue #%d not found in %s", number, repo)
+		}
+		return err
+	}
+	return nil
+}
+
+// removeIssueLabel removes a label from an issue in GitHub. A label that is
+// already gone counts as removed.
+func removeIssueLabel(tracker github.Tracker, repo string, number int, label string) error {
+	if err := tracker.RemoveLabel(repo, number, label); err != nil && !errors.Is(err, github.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// setPhase replaces the phase label among an issue's labels with that of
+// phase, creating the label in the repo when needed, and returns the issue's
+// new labels
+func setPhase(tracker github.Tracker, workflow config.Workflow, repo string, number int, labels []string, phase config.Phase) ([]string, error) {
+	if err := ensureLabelExists(tracker, repo, phase); err != nil {
+		return labels, fmt.Errorf("failed to ensure label exists: %w", err)
+	}
+
+	var newLabels []string
+	var removeErr error
+	hasPhase := false
+	for _, l := range labels {
+		switch {
+		case strings.EqualFold(l, phase.Label):
+			hasPhase = true
+			newLabels = append(newLabels, l)
+		case workflow.IsPhase(l):
+			if err := removeIssueLabel(tracker, repo, number, l); err != nil {
+				removeErr = fmt.Errorf("failed to remove phase label: %w", err)
+			}
+		default:
+			newLabels = append(newLabels, l)
+		}
+	}
+	if hasPhase {
+		return newLabels, removeErr
+	}
+
+	if err := addIssueLabel(tracker, repo, number, phase.Label); err != nil {
+		return newLabels, fmt.Errorf("failed to add label: %w", err)
+	}
+	return append(newLabels, phase.Label), removeErr
+}
+
+// ensureLabelExists checks if the label of phase exists in a repository and
+// creates it if not
+func ensureLabelExists(tracker github.Tracker, repo string, phase config.Phase) error {
+	label := phase.Label
+	labels, err := tracker.ListLabels(repo)
+	if err != nil {
+		return fmt.Errorf("failed to list labels: %w", err)
+	}
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, label) {
+			return nil
+		}
+	}
+
+	err = tracker.CreateLabel(repo, github.Label{
+		Name:        label,
+		Color:       phase.Color,
+		Description: phase.Description,
+	})
+	if err != nil && !errors.Is(err, github.ErrAlreadyExists) {
+		return fmt.Errorf("failed to create label: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// New Issue Dialog (Issue #27)
+// =============================================================================
+
+func (m *model) openNewIssueDialog() {
+	m.showNewIssueDialog = true
+	m.newIssueDialogMode = "repo-select"
+	m.newIssueSelectedRepo = 0
+	m.newIssueFilterText = ""
+	m.newIssueErrorMessage = ""
+
+	// Fetch user's repos
+	repos, err := fetchUserRepos(m.tracker, m.config.GitHub)
+	if err != nil {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = err.Error()
+		return
+	}
+
+	m.newIssueRepos = repos
+	m.newIssueFilteredRepos = repos
+}
+
+func (m *model) filterNewIssueRepos() {
+	if m.newIssueFilterText == "" {
+		m.newIssueFilteredRepos = m.newIssueRepos
+		if m.newIssueSelectedRepo >= len(m.newIssueFilteredRepos) {
+			m.newIssueSelectedRepo = 0
+		}
+		return
+	}
+
+	query := strings.ToLower(m.newIssueFilterText)
+	m.newIssueFilteredRepos = nil
+	for _, repo := range m.newIssueRepos {
+		if fuzzyMatchRepo(repo, query) {
+			m.newIssueFilteredRepos = append(m.newIssueFilteredRepos, repo)
+		}
+	}
+
+	if m.newIssueSelectedRepo >= len(m.newIssueFilteredRepos) {
+		m.newIssueSelectedRepo = 0
+	}
+}
+
+func fuzzyMatchRepo(text, query string) bool {
+	textLower := strings.ToLower(text)
+	queryIdx := 0
+	for _, c := range textLower {
+		if queryIdx < len(query) && string(c) == string(query[queryIdx]) {
+			queryIdx++
+		}
+	}
+	return queryIdx == len(query)
+}
+
+func (m *model) executeNewIssueSelection() {
+	if len(m.newIssueFilteredRepos) == 0 {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "No repository selected"
+		return
+	}
+
+	// Get the selected repo
+	selectedRepo := m.newIssueFilteredRepos[m.newIssueSelectedRepo]
+
+	// Convert GitHub repo name to local path
+	localRepoPath := m.config.Local.RepoPath(selectedRepo)
+
+	// Try to find a matching tmuxinator session
+	muxProject := findMatchingTmuxinatorSession(selectedRepo)
+	sessionName := muxProject
+	if sessionName == "" {
+		sessionName = strings.ReplaceAll(selectedRepo, "/", "-")
+	}
+
+	// Check if tmux session exists
+	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	if err := checkCmd.Run(); err != nil {
+		// Session doesn't exist
+		if muxProject != "" {
+			// Use tmuxinator to start the project
+			startCmd := exec.Command("tmuxinator", "start", muxProject, "-d")
+			if err := startCmd.Run(); err != nil {
+				m.newIssueDialogMode = "error"
+				m.newIssueErrorMessage = "Failed to start tmuxinator project"
+				return
+			}
+		} else {
+			// Create new session manually
+			createCmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-n", "main")
+			if err := createCmd.Run(); nil != err {
+				m.newIssueDialogMode = "error"
+				m.newIssueErrorMessage = "Failed to create tmux session"
+				return
+			}
+		}
+	}
+
+	// Execute tmux command to open new window in the repo's session
+	cmd := exec.Command("tmux", "new-window", "-d", "-n", "opencode-issue", "-t", sessionName, "-c", localRepoPath)
+	if err := cmd.Run(); err != nil {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "Failed to create tmux window"
+		return
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	// Build the prompt with /issue (title will be entered in the new tab)
+	fullCommand := m.agentCommand("/issue")
+
+	// Send the command to the new window in the repo's session
+	cmd = exec.Command("tmux", "send-keys", "-t", fmt.Sprintf("%s:opencode-issue", sessionName), fullCommand, "Enter")
+	if err := cmd.Run(); err != nil {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "Failed to send command to tmux window"
+		return
+	}
+
+	// Switch to the new window in the repo's session
+	selectCmd := exec.Command("bash", "-c", fmt.Sprintf("tmux select-window -t %s:opencode-issue && tmux switch-client -t %s", sessionName, sessionName))
+	if err := selectCmd.Run(); err != nil {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "Failed to switch to tmux window"
+		return
+	}
+
+	// Close the dialog
+	m.showNewIssueDialog = false
+	m.newIssueDialogMode = ""
+	m.newIssueTitle = ""
+	m.newIssueFilterText = ""
+}
+
+func (m *model) executeIssueTitleInput() {
+	if m.newIssueTitle == "" {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "Issue title cannot be empty"
+		return
+	}
+
+	// Get the selected repo
+	selectedRepo := m.newIssueFilteredRepos[m.newIssueSelectedRepo]
+
+	// Convert GitHub repo name to local path
+	localRepoPath := m.config.Local.RepoPath(selectedRepo)
+
+	// Try to find a matching tmuxinator session
+	muxProject := findMatchingTmuxinatorSession(selectedRepo)
+	sessionName := muxProject
+	if sessionName == "" {
+		sessionName = strings.ReplaceAll(selectedRepo, "/", "-")
+	}
+
+	// Check if tmux session exists
+	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	if err := checkCmd.Run(); err != nil {
+		// Session doesn't exist
+		if muxProject != "" {
+			// Use tmuxinator to start the project
+			startCmd := exec.Command("tmuxinator", "start", muxProject, "-d")
+			if err := startCmd.Run(); err != nil {
+				m.newIssueDialogMode = "error"
+				m.newIssueErrorMessage = "Failed to start tmuxinator project"
+				return
+			}
+		} else {
+			// Create new session manually
+			createCmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-n", "main")
+			if err := createCmd.Run(); nil != err {
+				m.newIssueDialogMode = "error"
+				m.newIssueErrorMessage = "Failed to create tmux session"
+				return
+			}
+		}
+	}
+
+	// Execute tmux command to open new window in the repo's session
+	cmd := exec.Command("tmux", "new-window", "-d", "-n", "opencode-issue", "-t", sessionName, "-c", localRepoPath)
+	if err := cmd.Run(); err != nil {
+		m.newIssueDialogMode = "error"
+		m.newIssueErrorMessage = "Failed to create tmux window"
+		return
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	// Build the prompt with the issue title
+	fullCommand := m.agentCommand("/issue " + m.newIssueTitle)
+
+	// Send the command to the new window in the repo's session
+	cmd = exec.Command("tmux", "send-keys", "-t", fmt.Sprintf("%s:opencode-issue", sessionName), fullCommand, "Enter")
+	_ = cmd.Run()
+
+	// Switch to the new window in the repo's session
+	selectCmd := exec.Command("bash", "-c", fmt.Sprintf("tmux select-window -t %s:opencode-issue && tmux switch-client -t %s", sessionName, sessionName))
+	_ = selectCmd.Run()
+
+	// Close the dialog
+	m.showNewIssueDialog = false
+	m.newIssueDialogMode = ""
+	m.newIssueTitle = ""
+	m.newIssueFilterText = ""
+}
+
+// resolveAgentRepos sets the Repo of agents linked to an issue to the repo
+// their command was launched for, as spelled by the issues, the queue and the
+// pipelines. Exit statuses and pipelines are keyed on that spelling, while the
+// origin remote may differ in case or, behind an ssh host alias, name no repo
+// at all; such agents are matched on the checkout they run in.
+func (m *model) resolveAgentRepos(agents []agent.Agent) {
+	var repos []string
+	seen := make(map[string]bool)
+	add := func(repo string) {
+		if repo != "" && !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	for _, is := range m.issues {
+		add(is.Repo)
+	}
+	for _, job := range m.queue.Jobs() {
+		add(job.Repo)
+	}
+	for _, run := range m.pipelineRuns {
+		add(run.Repo)
+	}
+	for _, repo := range m.config.GitHub.Repos {
+		add(repo)
+	}
+
+	for i := range agents {
+		if agents[i].Issue > 0 {
+			agents[i].Repo = launchedRepo(agents[i], repos, m.config.Local)
+		}
+	}
+}
+
+// launchedRepo returns the repo among repos that a was launched for: the one
+// its remote names regardless of case, else for agents without a remote repo
+// the one whose checkout or issue worktrees hold its working directory.
+func launchedRepo(a agent.Agent, repos []string, local config.Local) string {
+	for _, repo := range repos {
+		if strings.EqualFold(a.Repo, repo) {
+			return repo
+		}
+	}
+	if a.Repo != "" || a.WorkingDir == "" {
+		return a.Repo
+	}
+	for _, repo := range repos {
+		checkout := local.RepoPath(repo)
+		if checkout != "" && (inDir(a.WorkingDir, checkout) || inDir(a.WorkingDir, agent.WorktreesDir(checkout))) {
+			return repo
+		}
+	}
+	return ""
+}
+
+// inDir reports whether path is dir or lies below it
+func inDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// readExitStatus reads the exit status recorded for an agent linked to an
+// issue, unless it is being read already. With poll a missing status is
+// expected.
+func (m *model) readExitStatus(a agent.Agent, poll bool) tea.Cmd {
+	if a.Repo == "" || a.Issue == 0 || a.Command == "" {
+		return nil
+	}
+	statusPath := agent.ExitStatusPath(m.exitDir, a.Repo, a.Issue, a.Command)
+	if m.exitReads[statusPath] {
+		return nil
+	}
+	m.exitReads[statusPath] = true
+	return func() tea.Msg {
+		status, err := agent.ReadExitStatus(statusPath)
+		return agentFinished{agent: a, status: status, err: err, poll: poll}
+	}
+}
+
+// awaitExitStatus reports whether the missing exit status at path may still
+// be written, remembering a so the status is read again on the next scans
+func (m *model) awaitExitStatus(path string, a agent.Agent) bool {
+	p, ok := m.exitsPending[path]
+	if !ok {
+		p = pendingExit{agent: a, since: time.Now()}
+		m.exitsPending[path] = p
+	}
+	return time.Since(p.since) < exitStatusGrace
+}
+
+// advanceAfterExit moves the issue of a finished agent on to the phase after
+// its command's phase, provided the command advances, the agent exited with
+// status zero and the issue is still in that phase. Issues running a pipeline
+// are left to continuePipeline.
+func (m *model) advanceAfterExit(f agentFinished) tea.Cmd {
+	a := f.agent
+	if f.err != nil {
+		return nil
+	}
+	if run, ok := m.pipelineRuns[pipeline.Key(a.Repo, a.Issue)]; ok && run.Status == pipeline.StatusRunning {
+		return nil
+	}
+	command, ok := m.config.CommandNamed(a.Command)
+	if !ok || !command.Advance {
+		return nil
+	}
+	workflow := m.config.Workflow(a.Repo)
+	next, ok := workflow.After(command.Phase)
+	if !ok {
+		return nil
+	}
+	tracker := m.tracker
+	return func() tea.Msg {
+		result := phaseAdvanced{repo: a.Repo, number: a.Issue, phase: next.Label, status: f.status}
+		if f.status != 0 {
+			return result
+		}
+		issue, err := tracker.GetIssue(a.Repo, a.Issue)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		if current, ok := workflow.Current(issue.Labels); !ok || !strings.EqualFold(current.Label, command.Phase) {
+			return nil
+		}
+		result.labels, result.err = setPhase(tracker, workflow, a.Repo, a.Issue, issue.Labels, next)
+		return result
+	}
+}
+
+// findIssue returns the index of an issue in m.issues, or -1
+func (m *model) findIssue(repo string, number int) int {
+	for i, is := range m.issues {
+		if is.Repo == repo && is.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// quickSelectHint describes the number keys that select one of n entries
+func quickSelectHint(n int) string {
+	n = min(n, maxQuickSelect)
+	if n == 1 {
+		return "1: Snabbval"
+	}
+	return fmt.Sprintf("1-%d: Snabbval", n)
+}
+
+// agentCommand returns the shell line that starts the configured agent with
+// prompt, quoted for tmux send-keys.
+func (m *model) agentCommand(prompt string) string {
+	return agent.ShellJoin(m.config.Agent.Command(prompt))
+}
+
+func findMatchingTmuxinatorSession(repo string) string {
+	homeDir := os.Getenv("HOME")
+	configDir := homeDir + "/.config/tmuxinator"
+
+	cmd := exec.Command("bash", "-c", "tmuxinator list")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(out), "\n")[1:]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		for _, projectName := range fields {
+			projectName = strings.TrimSpace(projectName)
+			if projectName == "" {
+				continue
+			}
+
+			configPath := configDir + "/" + projectName + ".yml"
+			data, err := os.ReadFile(configPath)
+			if err != nil {
+				continue
+			}
+
+			content := string(data)
+			for _, line := range strings.Split(content, "\n") {
+				if strings.HasPrefix(line, "root:") {
+					rootPath := strings.TrimSpace(strings.TrimPrefix(line, "root:"))
+					rootPath = os.ExpandEnv(rootPath)
+					rootPath = strings.TrimRight(rootPath, "/")
+
+					parts := strings.Split(rootPath, "/")
+					folderName := parts[len(parts)-1]
+
+					repoOwner, repoName, _ := strings.Cut(repo, "/")
+					searchName := repoName
+					if searchName == "" {
+						searchName = repoOwner
+					}
+
+					if strings.EqualFold(folderName, searchName) {
+						return projectName
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func fetchUserRepos(tracker github.Tracker, sources config.GitHub) ([]string, error) {
+	repoData, err := tracker.ListUserRepos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	var repos []string
+	listed := make(map[string]bool)
+	for _, r := range repoData {
+		listed[r.FullName] = true
+		if r.Archived || sources.Excluded(r.FullName) {
+			continue
+		}
+		repos = append(repos, r.FullName)
+	}
+	// Configured repos may belong to someone the user does not collaborate with
+	for _, repo := range sources.Repos {
+		if !listed[repo] && !sources.Excluded(repo) {
+			listed[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+
+	return repos, nil
+}
+
+func (m *model) renderNewIssueDialog(width, height int) string {
+	dialogWidth := newIssueDialogWidth
+	dialogHeight := newIssueDialogHeight
+
+	if dialogWidth > width-4 {
+		dialogWidth = width - 4
+	}
+	if dialogHeight > height-4 {
+		dialogHeight = height - 4
+	}
+
+	var content string
+
+	if m.newIssueDialogMode == "error" {
+		content = errorStyle.Render("Error: "+m.newIssueErrorMessage) + "\n\n" +
+			mutedStyle.Render("Press any key to close...")
+	} else {
+		// Repo selection mode
+		content = titleStyle.Render("Create New Issue") + "\n\n" +
+			mutedStyle.Render("Select repository:") + "\n\n"
+
+		// Show filter text
+		if m.newIssueFilterText != "" {
+			content += mutedStyle.Render("Filter: ") + m.newIssueFilterText + "\n\n"
+		}
+
+		// Show repos
+		maxVisible := dialogHeight - 10
+		if len(m.newIssueFilteredRepos) > maxVisible {
+			m.newIssueFilteredRepos = m.newIssueFilteredRepos[:maxVisible]
+		}
+
+		for i, repo := range m.newIssueFilteredRepos {
+			prefix := "   "
+			style := mutedStyle
+			if i == m.newIssueSelectedRepo {
+				prefix = " > "
+				style = selectedItemStyle
+			}
+			repoName := repo
+			if idx := strings.LastIndex(repo, "/"); idx >= 0 {
+				repoName = repo[idx+1:]
+			}
+			content += style.Render(fmt.Sprintf("%s%s", prefix, repoName)) + "\n"
+		}
+
+		if len(m.newIssueFilteredRepos) == 0 {
+			content += mutedStyle.Render("No repositories found") + "\n"
+		}
+
+		content += "\n" + mutedStyle.Render("↑/↓: navigate  1-9: select  Enter: confirm  n/Esc: close")
+	}
+
+	dialog := lipgloss.NewStyle().
+		Width(dialogWidth).
+		Height(dialogHeight).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Background(lipgloss.Color("236")).
+		Foreground(lipgloss.Color("252")).
+		Padding(1)
+
+	return dialog.Render(content)
+}
+
+func (m *model) renderNewIssueDialogRaw(width, height int) string {
+	dialogWidth := newIssueDialogWidth
+	dialogHeight := newIssueDialogHeight
+
+	if dialogWidth > width-4 {
+		dialogWidth = width - 4
+	}
+	if dialogHeight > height-4 {
+		dialogHeight = height - 4
+	}
+
+	var content string
+
+	if m.newIssueDialogMode == "error" {
+		content = errorStyle.Render("Error: "+m.newIssueErrorMessage) + "\n\n" +
+			mutedStyle.Render("Press any key to close...")
+	} else if m.newIssueDialogMode == "issue-input" {
+		selectedRepo := m.newIssueFilteredRepos[m.newIssueSelectedRepo]
+		repoName := selectedRepo
+		if idx := strings.LastIndex(selectedRepo, "/"); idx >= 0 {
+			repoName = selectedRepo[idx+1:]
+		}
+
+		content = titleStyle.Render("Create New Issue") + "\n\n" +
+			mutedStyle.Render("Repository: "+repoName) + "\n\n" +
+			mutedStyle.Render("Issue title:") + "\n\n" +
+			selectedItemStyle.Render("  > "+m.newIssueTitle+"_") + "\n\n\n" +
+			mutedStyle.Render("Enter: Skapa  |  Backspace: Ta bort  |  Esc: Avbryt")
+	} else {
+		content = titleStyle.Render("Create New Issue") + "\n\n" +
+			mutedStyle.Render("Select repository:") + "\n\n"
+
+		if m.newIssueFilterText != "" {
+			content += mutedStyle.Render("Filter: ") + m.newIssueFilterText + "\n\n"
+		}
+
+		maxVisible := dialogHeight - 10
+		if len(m.newIssueFilteredRepos) > maxVisible {
+			m.newIssueFilteredRepos = m.newIssueFilteredRepos[:maxVisible]
+		}
+
+		for i, repo := range m.newIssueFilteredRepos {
+			prefix := "   "
+			style := mutedStyle
+			if i == m.newIssueSelectedRepo {
+				prefix = " > "
+				style = selectedItemStyle
+			}
+			repoName := repo
+			if idx := strings.LastIndex(repo, "/"); idx >= 0 {
+				repoName = repo[idx+1:]
+			}
+			content += style.Render(fmt.Sprintf("%s%s", prefix, repoName)) + "\n"
+		}
+
+		if len(m.newIssueFilteredRepos) == 0 {
+			content += mutedStyle.Render("No repositories found") + "\n"
+		}
+
+		content += "\n" + mutedStyle.Render("↑/↓: navigate  1-9: select  Enter: confirm  n/Esc: close")
+	}
+
+	dialog := lipgloss.NewStyle().
+		Width(dialogWidth).
+		Height(dialogHeight).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Background(lipgloss.Color("236")).
+		Foreground(lipgloss.Color("252")).
+		Padding(1)
+
+	return dialog.Render(content)
+}